@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+func newAllowCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "allow",
+		Short: "Allow a Tenant to use cluster-scoped classes",
+		Long: `Add cluster-scoped classes to the allow-list of a Tenant.
+
+The class is added to spec.<field>.allowed of the Tenant CR, and the command
+waits until the operator publishes it in status.<field>.available.`,
+	}
+
+	for resourceName, opts := range ClusterResources {
+		if opts.allowListField == "" {
+			continue
+		}
+		cmd.AddCommand(newAllowListCmd(resourceName, opts, true, configFlags, ioStreams))
+	}
+
+	return cmd
+}
+
+func newDisallowCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disallow",
+		Short: "Stop a Tenant from using cluster-scoped classes",
+		Long: `Remove cluster-scoped classes from the allow-list of a Tenant.
+
+The class is removed from spec.<field>.allowed of the Tenant CR, and the command
+waits until the operator drops it from status.<field>.available.`,
+	}
+
+	for resourceName, opts := range ClusterResources {
+		if opts.allowListField == "" {
+			continue
+		}
+		cmd.AddCommand(newAllowListCmd(resourceName, opts, false, configFlags, ioStreams))
+	}
+
+	return cmd
+}
+
+func newAllowListCmd(resourceName string, opts getOptions, allow bool, configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var wait bool
+	var timeout time.Duration
+
+	verb := "allow"
+	short := fmt.Sprintf("Allow %s for a Tenant", resourceName)
+	if !allow {
+		verb = "disallow"
+		short = fmt.Sprintf("Disallow %s for a Tenant", resourceName)
+	}
+
+	cmd := &cobra.Command{
+		Use:   resourceName + " <tenant> <name>...",
+		Short: short,
+		Long: fmt.Sprintf(`%s.
+
+This edits spec.%s.allowed of the Tenant CR and waits until
+status.%s.available reflects the change.`, short, opts.allowListField, opts.statusField),
+		Example: fmt.Sprintf(`  # %s a %s for my-tenant
+  kubectl tenant %s %s my-tenant my-class
+
+  # Preview the change without persisting it
+  kubectl tenant %s %s my-tenant my-class --dry-run=server`,
+			strings.ToUpper(verb[:1])+verb[1:], resourceName, verb, resourceName, verb, resourceName),
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName, names := args[0], args[1:]
+
+			dryRun, err := cmdutil.GetDryRunStrategy(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			// Only classes that exist can be allowed; disallowing also has to work for deleted ones.
+			if allow {
+				for _, name := range names {
+					if _, err := dyn.Resource(opts.resource).Get(ctx, name, metav1.GetOptions{}); err != nil {
+						if apierrors.IsNotFound(err) {
+							return fmt.Errorf("%s %q not found", resourceName, name)
+						}
+						return err
+					}
+				}
+			}
+
+			before, after, err := updateTenantSpec(ctx, dyn, tenantName, []string{opts.allowListField}, dryRun,
+				func(field map[string]interface{}) error {
+					if allow {
+						field["allowed"] = addNames(field["allowed"], names)
+					} else {
+						field["allowed"] = removeNames(field["allowed"], names)
+					}
+					return nil
+				})
+			if err != nil {
+				return err
+			}
+
+			if err := printFieldDiff(ioStreams.Out, opts.allowListField, before, after); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			if err := printPatchResult(ioStreams.Out, tenantName, before, after, dryRun); err != nil {
+				return err
+			}
+
+			if !wait || dryRun != cmdutil.DryRunNone {
+				return nil
+			}
+			return waitForAllowList(ctx, dyn, tenantName, opts, names, allow, timeout)
+		},
+	}
+
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().BoolVar(&wait, "wait", true, "Wait until the Tenant status reflects the change")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait for the Tenant status")
+
+	return cmd
+}
+
+// waitForAllowList waits until all names are present in (allow) or absent from
+// (disallow) the available list the operator publishes in the Tenant status.
+func waitForAllowList(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantName string,
+	opts getOptions,
+	names []string,
+	allow bool,
+	timeout time.Duration,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err := waitForTenant(ctx, dyn, tenantName, func(u *unstructured.Unstructured) bool {
+		available := extractAvailableNames(u, opts.statusField)
+		for _, name := range names {
+			if slices.Contains(available, name) != allow {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out waiting for status.%s.available of tenant %q to reflect the change",
+				opts.statusField, tenantName)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// applyObject is a manifest with the resource it is applied to.
+type applyObject struct {
+	obj     *unstructured.Unstructured
+	mapping *meta.RESTMapping
+}
+
+func (a applyObject) String() string {
+	return fmt.Sprintf("%s/%s", a.mapping.Resource.GroupResource().String(), a.obj.GetName())
+}
+
+// applyRefusal is an object apply won't send to the cluster, with the reason.
+type applyRefusal struct {
+	obj    *unstructured.Unstructured
+	reason string
+}
+
+func newApplyCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var forceConflicts bool
+	manifests := &manifestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "apply <tenant> -f <manifests>",
+		Short: "Apply manifests after checking them against the constraints of a Tenant",
+		Long: `Apply manifests after checking them against the constraints of a Tenant.
+
+Objects are refused when they
+  - are cluster-scoped, or target a namespace that isn't a tenant namespace
+  - use a StorageClass, IngressClass or PriorityClass that isn't available
+    to the tenant
+
+Objects without a namespace go to the namespace of the current context. When
+an object is refused nothing is applied. Otherwise all objects are first
+applied with a server-side dry run, so that admission webhooks see them, and
+only when that succeeds for every object are they server-side applied.
+
+With --dry-run=client only the tenant constraints are checked; with
+--dry-run=server the dry run is the last step.`,
+		Example: `  # Apply a directory of manifests to my-tenant
+  kubectl tenant apply my-tenant -f manifests/
+
+  # Check the manifests without applying them
+  kubectl tenant apply my-tenant -f manifests/ --dry-run=server`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+			dryRun, err := cmdutil.GetDryRunStrategy(cmd)
+			if err != nil {
+				return err
+			}
+
+			objs, err := manifests.read(ioStreams.In)
+			if err != nil {
+				return err
+			}
+			if len(objs) == 0 {
+				return fmt.Errorf("no objects found in %s", strings.Join(manifests.filenames, ", "))
+			}
+			defaultNamespace, _, err := configFlags.ToRawKubeConfigLoader().Namespace()
+			if err != nil {
+				return err
+			}
+			mapper, err := configFlags.ToRESTMapper()
+			if err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			tenant, err := getTenant(ctx, dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			toApply, refusals := checkTenantConstraints(tenant, objs, mapper, defaultNamespace)
+			if len(refusals) > 0 {
+				if err := printApplyRefusals(ioStreams.Out, refusals); err != nil {
+					return err
+				}
+				return fmt.Errorf("refused %d of %d objects for tenant %q; nothing was applied",
+					len(refusals), len(objs), tenantName)
+			}
+
+			if dryRun != cmdutil.DryRunClient {
+				// Apply nothing unless the API server accepts every object
+				var failed int
+				for _, a := range toApply {
+					if err := serverSideApply(ctx, dyn, a, forceConflicts, true); err != nil {
+						failed++
+						_, _ = fmt.Fprintf(ioStreams.ErrOut, "error: %s: %v\n", a, err)
+					}
+				}
+				if failed > 0 {
+					return fmt.Errorf("the API server rejected %d of %d objects; nothing was applied", failed, len(toApply))
+				}
+			}
+
+			var b strings.Builder
+			for _, a := range toApply {
+				if dryRun == cmdutil.DryRunNone {
+					if err := serverSideApply(ctx, dyn, a, forceConflicts, false); err != nil {
+						_, _ = io.WriteString(ioStreams.Out, b.String())
+						return fmt.Errorf("apply %s: %w", a, err)
+					}
+				}
+				result := "serverside-applied"
+				switch dryRun {
+				case cmdutil.DryRunClient:
+					result += " (dry run)"
+				case cmdutil.DryRunServer:
+					result += " (server dry run)"
+				}
+				fmt.Fprintf(&b, "%s %s\n", a, result)
+			}
+			if _, err := io.WriteString(ioStreams.Out, b.String()); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	manifests.addFlags(cmd, "Files or directories with the manifests to apply, or - for stdin")
+	_ = cmd.MarkFlagRequired("filename")
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().BoolVar(&forceConflicts, "force-conflicts", false,
+		"Take over fields managed by other field managers")
+
+	return cmd
+}
+
+// checkTenantConstraints resolves the resource of every object and checks it
+// targets a tenant namespace and only uses classes available to the tenant.
+// Objects without a namespace are put in defaultNamespace.
+func checkTenantConstraints(
+	tenant *unstructured.Unstructured,
+	objs []*unstructured.Unstructured,
+	mapper meta.RESTMapper,
+	defaultNamespace string,
+) ([]applyObject, []applyRefusal) {
+	namespaces := extractNamespaceNames(tenant)
+
+	var toApply []applyObject
+	var refusals []applyRefusal
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			refusals = append(refusals, applyRefusal{obj: obj, reason: fmt.Sprintf("unknown resource type: %v", err)})
+			continue
+		}
+
+		if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+			refusals = append(refusals, applyRefusal{obj: obj, reason: "cluster-scoped objects can't be applied for a tenant"})
+			continue
+		}
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(defaultNamespace)
+		}
+		if !slices.Contains(namespaces, obj.GetNamespace()) {
+			refusals = append(refusals, applyRefusal{obj: obj,
+				reason: fmt.Sprintf("namespace %q is not a namespace of tenant %q", obj.GetNamespace(), tenant.GetName())})
+			continue
+		}
+
+		var denied []string
+		for _, ref := range classReferencesOf(obj) {
+			if !slices.Contains(ClusterResources[ref.resource].extractTenantResources(tenant), ref.name) {
+				denied = append(denied, fmt.Sprintf("%s %q is not permitted for tenant %q", ref.field, ref.name, tenant.GetName()))
+			}
+		}
+		if len(denied) > 0 {
+			refusals = append(refusals, applyRefusal{obj: obj, reason: strings.Join(denied, "; ")})
+			continue
+		}
+
+		toApply = append(toApply, applyObject{obj: obj, mapping: mapping})
+	}
+	return toApply, refusals
+}
+
+// serverSideApply applies the object with the plugin's field manager.
+func serverSideApply(ctx context.Context, dyn dynamic.Interface, a applyObject, force, dryRun bool) error {
+	data, err := json.Marshal(a.obj.Object)
+	if err != nil {
+		return err
+	}
+	opts := metav1.PatchOptions{FieldManager: fieldManager, Force: &force}
+	if dryRun {
+		opts.DryRun = []string{metav1.DryRunAll}
+	}
+	_, err = dyn.Resource(a.mapping.Resource).Namespace(a.obj.GetNamespace()).
+		Patch(ctx, a.obj.GetName(), types.ApplyPatchType, data, opts)
+	return err
+}
+
+func printApplyRefusals(out io.Writer, refusals []applyRefusal) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAMESPACE\tKIND\tNAME\tREASON"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, r := range refusals {
+		namespace := r.obj.GetNamespace()
+		if namespace == "" {
+			namespace = "<none>"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", namespace, r.obj.GetKind(), r.obj.GetName(), r.reason); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+)
+
+// auditedResources are the namespaced resources audit scans for class references.
+var auditedResources = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "persistentvolumeclaims"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	{Version: "v1", Resource: "pods"},
+	{Version: "v1", Resource: "replicationcontrollers"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "replicasets"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "batch", Version: "v1", Resource: "jobs"},
+	{Group: "batch", Version: "v1", Resource: "cronjobs"},
+}
+
+// sarifRules describe the violations audit reports in SARIF, keyed by the
+// ClusterResources entry of the class.
+var sarifRules = map[string]sarifRule{
+	"storageclasses": {
+		ID:               "disallowed-storage-class",
+		ShortDescription: sarifMessage{Text: "StorageClass not permitted for the tenant"},
+	},
+	"ingressclasses": {
+		ID:               "disallowed-ingress-class",
+		ShortDescription: sarifMessage{Text: "IngressClass not permitted for the tenant"},
+	},
+	"priorityclasses": {
+		ID:               "disallowed-priority-class",
+		ShortDescription: sarifMessage{Text: "PriorityClass not permitted for the tenant"},
+	},
+}
+
+// classReference is a class named by a field of an object.
+type classReference struct {
+	resource string
+	field    string
+	name     string
+}
+
+// auditViolation is an object using a class the tenant isn't permitted to use.
+type auditViolation struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Field     string `json:"field"`
+	Resource  string `json:"resource"`
+	Class     string `json:"class"`
+}
+
+func newAuditCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "audit <tenant>",
+		Short: "Find workloads using classes a Tenant isn't permitted to use",
+		Long: `Find workloads using classes a Tenant isn't permitted to use.
+
+The PersistentVolumeClaims, Ingresses, Pods and pod templates of workloads in
+every tenant namespace are scanned, and their storageClassName,
+ingressClassName and priorityClassName, including those of StatefulSet volume
+claim templates and ephemeral volumes, are compared to the classes available
+to the tenant. Objects that don't name a class, and so use the cluster
+default, are not reported. Pods created by a scanned workload are reported
+through the workload.
+
+The command exits with a non-zero status when violations are found.`,
+		Example: `  # Audit my-tenant
+  kubectl tenant audit my-tenant
+
+  # Write the violations as SARIF for code scanning
+  kubectl tenant audit my-tenant -o sarif > audit.sarif`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+			if output != "" && output != "json" && output != "sarif" {
+				return fmt.Errorf("unsupported output format %q: must be one of json, sarif", output)
+			}
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			tenant, err := getTenant(cmd.Context(), dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			violations, err := auditTenant(cmd.Context(), dyn, tenant)
+			if err != nil {
+				return err
+			}
+			if err := printAuditViolations(ioStreams.Out, output, tenantName, violations); err != nil {
+				return err
+			}
+			if len(violations) > 0 {
+				return fmt.Errorf("found %d objects using classes not permitted for tenant %q", len(violations), tenantName)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json or sarif")
+
+	return cmd
+}
+
+// auditTenant lists the audited resources of every tenant namespace and returns
+// the class references the tenant isn't permitted, sorted by namespace, kind and name.
+func auditTenant(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenant *unstructured.Unstructured,
+) ([]auditViolation, error) {
+	permitted := map[string][]string{}
+	for resource := range sarifRules {
+		permitted[resource] = ClusterResources[resource].extractTenantResources(tenant)
+	}
+
+	var violations []auditViolation
+	for _, ns := range extractNamespaceNames(tenant) {
+		for _, gvr := range auditedResources {
+			list, err := dyn.Resource(gvr).Namespace(ns).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("list %s in namespace %q: %w", gvr.Resource, ns, err)
+			}
+			for i := range list.Items {
+				obj := &list.Items[i]
+				if ownedByWorkload(obj) {
+					continue
+				}
+				for _, ref := range classReferencesOf(obj) {
+					if slices.Contains(permitted[ref.resource], ref.name) {
+						continue
+					}
+					violations = append(violations, auditViolation{
+						Namespace: ns,
+						Kind:      obj.GetKind(),
+						Name:      obj.GetName(),
+						Field:     ref.field,
+						Resource:  ref.resource,
+						Class:     ref.name,
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return violations, nil
+}
+
+// ownedByWorkload reports whether obj is controlled by a workload that is audited
+// itself, such as the ReplicaSet of a Deployment or the Pods of a Job.
+func ownedByWorkload(obj *unstructured.Unstructured) bool {
+	if ref := metav1.GetControllerOf(obj); ref != nil {
+		_, ok := podSpecPaths[ref.Kind]
+		return ok
+	}
+	return false
+}
+
+// classReferencesOf returns the classes obj names. Fields that are empty, so
+// that the cluster default is used, are left out.
+func classReferencesOf(obj *unstructured.Unstructured) []classReference {
+	var out []classReference
+	add := func(resource string, fields ...string) {
+		if name, _, _ := unstructured.NestedString(obj.Object, fields...); name != "" {
+			out = append(out, classReference{resource: resource, field: strings.Join(fields, "."), name: name})
+		}
+	}
+
+	switch obj.GetKind() {
+	case "PersistentVolumeClaim":
+		add("storageclasses", "spec", "storageClassName")
+	case "Ingress":
+		add("ingressclasses", "spec", "ingressClassName")
+	}
+
+	path, ok := podSpecPaths[obj.GetKind()]
+	if !ok {
+		return out
+	}
+	add("priorityclasses", append(slices.Clone(path), "priorityClassName")...)
+
+	// Generic ephemeral volumes create a PVC for every pod
+	volumes, _, _ := unstructured.NestedSlice(obj.Object, append(slices.Clone(path), "volumes")...)
+	for i, v := range volumes {
+		volume, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(volume, "ephemeral", "volumeClaimTemplate", "spec", "storageClassName")
+		if name != "" {
+			out = append(out, classReference{
+				resource: "storageclasses",
+				field: fmt.Sprintf("%s.volumes[%d].ephemeral.volumeClaimTemplate.spec.storageClassName",
+					strings.Join(path, "."), i),
+				name: name,
+			})
+		}
+	}
+
+	if obj.GetKind() == "StatefulSet" {
+		templates, _, _ := unstructured.NestedSlice(obj.Object, "spec", "volumeClaimTemplates")
+		for i, t := range templates {
+			template, ok := t.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, _, _ := unstructured.NestedString(template, "spec", "storageClassName"); name != "" {
+				out = append(out, classReference{
+					resource: "storageclasses",
+					field:    fmt.Sprintf("spec.volumeClaimTemplates[%d].spec.storageClassName", i),
+					name:     name,
+				})
+			}
+		}
+	}
+	return out
+}
+
+func printAuditViolations(out io.Writer, format, tenantName string, violations []auditViolation) error {
+	switch format {
+	case "json":
+		if violations == nil {
+			violations = []auditViolation{}
+		}
+		data, err := json.MarshalIndent(violations, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "sarif":
+		data, err := json.MarshalIndent(auditSARIF(tenantName, violations), "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	}
+
+	if len(violations) == 0 {
+		if _, err := fmt.Fprintf(out, "No objects use classes not permitted for tenant %q.\n", tenantName); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAMESPACE\tKIND\tNAME\tFIELD\tCLASS"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, v := range violations {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v.Namespace, v.Kind, v.Name, v.Field, v.Class); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// SARIF 2.1.0 log, limited to the properties audit fills in.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// auditSARIF reports the violations as a SARIF log with a rule per class resource;
+// objects are logical locations named <namespace>/<kind>/<name>.
+func auditSARIF(tenantName string, violations []auditViolation) sarifLog {
+	resources := make([]string, 0, len(sarifRules))
+	for resource := range sarifRules {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	rules := make([]sarifRule, 0, len(resources))
+	for _, resource := range resources {
+		rules = append(rules, sarifRules[resource])
+	}
+
+	results := make([]sarifResult, 0, len(violations))
+	for _, v := range violations {
+		results = append(results, sarifResult{
+			RuleID: sarifRules[v.Resource].ID,
+			Level:  "error",
+			Message: sarifMessage{Text: fmt.Sprintf("%s %q in namespace %q sets %s to %q, which is not permitted for tenant %q",
+				v.Kind, v.Name, v.Namespace, v.Field, v.Class, tenantName)},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+				FullyQualifiedName: fmt.Sprintf("%s/%s/%s", v.Namespace, v.Kind, v.Name),
+				Kind:               "resource",
+			}}}},
+		})
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: "kubectl-tenant", Rules: rules}},
+			Results: results,
+		}},
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/kubectl/pkg/cmd/get"
+)
+
+func newDescribeCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe",
+		Short: "Show details of the resources of a Tenant",
+		Long:  `Show details of the resources of a Tenant.`,
+	}
+
+	cmd.AddCommand(newDescribeQuotaCmd(configFlags, ioStreams))
+
+	return cmd
+}
+
+func newDescribeQuotaCmd(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	offline := &offlineOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "quota <tenant>",
+		Aliases: []string{"quotas"},
+		Short:   "Show the hard limits and limit range of the quota of a Tenant",
+		Long: `Show the hard limits and limit range of the quota of a Tenant.
+
+The hard limits of the Quota CR are shown with their usage, and its limit range
+with the min, max and defaults per limit type. Every tenant namespace is
+checked against the limit range: namespaces whose LimitRanges differ from the
+Quota CR, or which have none, are flagged.
+
+With -f the Tenant is read from a manifest instead of the cluster; the Quota
+CR can be given in the same manifests or looked up with --resolve. A Quota in
+the manifests takes precedence over one in a --resolve directory, but not over
+the cluster with --resolve cluster. The usage and the LimitRanges of the
+namespaces are only shown with --resolve cluster.`,
+		Example: `  # Describe the quota of my-tenant
+  kubectl tenant describe quota my-tenant
+
+  # Describe the quota a Tenant manifest would use
+  kubectl tenant describe quota my-tenant -f tenant.yaml --resolve cluster`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			dyn, err := offline.dynamicClient(ctx, configFlags, ioStreams)
+			if err != nil {
+				return err
+			}
+
+			q, err := loadTenantQuota(ctx, dyn, args[0], ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			if offline.active() && isPermittedStub(q.quota) {
+				_, _ = fmt.Fprintf(ioStreams.ErrOut,
+					"Warning: Quota %q of tenant %q is not in the manifests; its limits are unknown without --resolve\n",
+					q.name, q.tenant)
+			}
+			limitRange, err := quotaLimitRange(q.quota)
+			if err != nil {
+				return err
+			}
+			if !offline.readsCluster() {
+				// The usage and LimitRanges of the namespaces are only known to the cluster
+				return printQuotaDescription(ioStreams.Out, q, limitRange, nil, false)
+			}
+			namespaces, err := checkNamespaceLimitRanges(ctx, dyn, q.namespaces, limitRange)
+			if err != nil {
+				return err
+			}
+			return printQuotaDescription(ioStreams.Out, q, limitRange, namespaces, true)
+		},
+	}
+
+	offline.addFlags(cmd)
+
+	return cmd
+}
+
+// printQuotaDescription prints the quota of a tenant. Without usage the USED
+// and USAGE columns and the LimitRange check of the namespaces are left out.
+func printQuotaDescription(
+	out io.Writer,
+	q *tenantQuota,
+	limitRange corev1.LimitRangeSpec,
+	namespaces []namespaceLimitRange,
+	usage bool,
+) error {
+	var b bytes.Buffer
+
+	if err := writeTable(&b, []string{"Name:\t" + q.name, "Tenant:\t" + q.tenant}); err != nil {
+		return err
+	}
+
+	if len(q.hard) == 0 {
+		b.WriteString("Hard Limits:  <none>\n")
+	} else {
+		b.WriteString("Hard Limits:\n")
+		rows := []string{"  RESOURCE\tHARD"}
+		if usage {
+			rows = []string{"  RESOURCE\tUSED\tHARD\tUSAGE"}
+		}
+		for _, r := range q.usageReport().Resources {
+			if usage {
+				rows = append(rows, fmt.Sprintf("  %s\t%s\t%s\t%.0f%%", r.Resource, r.Used.String(), r.Hard.String(), r.Percent))
+			} else {
+				rows = append(rows, fmt.Sprintf("  %s\t%s", r.Resource, r.Hard.String()))
+			}
+		}
+		if err := writeTable(&b, rows); err != nil {
+			return err
+		}
+	}
+
+	if len(limitRange.Limits) == 0 {
+		b.WriteString("Limit Range:  <none>\n")
+	} else {
+		b.WriteString("Limit Range:\n")
+		rows := []string{"  TYPE\tRESOURCE\tMIN\tMAX\tDEFAULT REQUEST\tDEFAULT LIMIT\tMAX LIMIT/REQUEST RATIO"}
+		for _, item := range limitRange.Limits {
+			for _, name := range limitRangeResources(item) {
+				rows = append(rows, fmt.Sprintf("  %s\t%s\t%s\t%s\t%s\t%s\t%s", item.Type, name,
+					quantityOrDash(item.Min, name), quantityOrDash(item.Max, name),
+					quantityOrDash(item.DefaultRequest, name), quantityOrDash(item.Default, name),
+					quantityOrDash(item.MaxLimitRequestRatio, name)))
+			}
+		}
+		if err := writeTable(&b, rows); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case !usage && len(q.namespaces) > 0:
+		b.WriteString("Namespaces:\n")
+		for _, ns := range q.namespaces {
+			b.WriteString("  " + ns + "\n")
+		}
+	case len(namespaces) == 0:
+		b.WriteString("Namespaces:  <none>\n")
+	default:
+		b.WriteString("Namespaces:\n")
+		rows := []string{"  NAMESPACE\tLIMITRANGE\tDIFFERENCES"}
+		for _, ns := range namespaces {
+			first := "<none>"
+			if len(ns.differences) > 0 {
+				first = ns.differences[0]
+			}
+			rows = append(rows, fmt.Sprintf("  %s\t%s\t%s", ns.namespace, ns.state, first))
+			for _, difference := range ns.differences[min(1, len(ns.differences)):] {
+				rows = append(rows, "  \t\t"+difference)
+			}
+		}
+		if err := writeTable(&b, rows); err != nil {
+			return err
+		}
+	}
+
+	if _, err := out.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// writeTable aligns the tab-separated rows into columns.
+func writeTable(out io.Writer, rows []string) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// limitRangeResources lists the resources a limit range item sets any value for.
+func limitRangeResources(item corev1.LimitRangeItem) []corev1.ResourceName {
+	all := corev1.ResourceList{}
+	lists := []corev1.ResourceList{item.Min, item.Max, item.Default, item.DefaultRequest, item.MaxLimitRequestRatio}
+	for _, list := range lists {
+		for name := range list {
+			all[name] = resource.Quantity{}
+		}
+	}
+	return sortedResourceNames(all)
+}
+
+func quantityOrDash(list corev1.ResourceList, name corev1.ResourceName) string {
+	q, ok := list[name]
+	if !ok {
+		return "-"
+	}
+	return q.String()
+}
+
+// printQuotaTable prints Quota CRs with their hard limits and limit range, for get -o wide.
+func printQuotaTable(
+	results []tenantResources,
+	withTenant bool,
+	printFlags *get.PrintFlags,
+	ioStreams genericiooptions.IOStreams,
+) error {
+	table := &metav1.Table{}
+	if withTenant {
+		table.ColumnDefinitions = append(table.ColumnDefinitions,
+			metav1.TableColumnDefinition{Name: "Tenant", Type: "string"})
+	}
+	table.ColumnDefinitions = append(table.ColumnDefinitions,
+		metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name"},
+		metav1.TableColumnDefinition{Name: "Hard", Type: "string"},
+		metav1.TableColumnDefinition{Name: "Limit Range", Type: "string"},
+		metav1.TableColumnDefinition{Name: "Age", Type: "string"},
+	)
+
+	for _, r := range results {
+		for _, item := range r.items {
+			hard, err := quotaHard(item)
+			if err != nil {
+				return err
+			}
+			limitRange, err := quotaLimitRange(item)
+			if err != nil {
+				return err
+			}
+
+			var cells []interface{}
+			if withTenant {
+				cells = append(cells, r.tenant)
+			}
+			cells = append(cells, item.GetName(), formatResourceList(hard), formatLimitRange(limitRange),
+				translateTimestampSince(item.GetCreationTimestamp()))
+			table.Rows = append(table.Rows, metav1.TableRow{Cells: cells})
+		}
+	}
+
+	p, err := printFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return p.PrintObj(table, ioStreams.Out)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// accessControlCategory is the diff category of the Tenant members.
+const accessControlCategory = "accessControl"
+
+// categoryDiff lists the entries only the second tenant has (added) and
+// only the first tenant has (removed).
+type categoryDiff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+type tenantDiff struct {
+	From       string                  `json:"from"`
+	To         string                  `json:"to"`
+	Categories map[string]categoryDiff `json:"categories"`
+}
+
+func newDiffCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "diff <tenant-a> <tenant-b>",
+		Short: "Compare the permissions of two Tenants",
+		Long: `Compare the permissions of two Tenants.
+
+Every resource allow-list of 'kubectl tenant get' is compared, together with the
+access control of the tenants. Namespaces are compared without their tenant
+prefix, so "a-dev" and "b-dev" are considered the same namespace.
+
+Entries only <tenant-b> has are shown with "+", entries only <tenant-a> has with "-".`,
+		Example: `  # Show how the new tenant differs from logistics
+  kubectl tenant diff logistics warehouse
+
+  # Compare as JSON for tooling
+  kubectl tenant diff logistics warehouse -o json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != "json" && output != "yaml" {
+				return fmt.Errorf("unsupported output format %q: must be one of json, yaml", output)
+			}
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			a, err := getTenant(ctx, dyn, args[0], ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			b, err := getTenant(ctx, dyn, args[1], ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			return printTenantDiff(ioStreams.Out, output, diffTenants(a, b))
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json or yaml (default: human-readable)")
+
+	return cmd
+}
+
+// diffTenants compares every ClusterResources allow-list and the access control of two tenants.
+func diffTenants(a, b *unstructured.Unstructured) tenantDiff {
+	d := tenantDiff{From: a.GetName(), To: b.GetName(), Categories: map[string]categoryDiff{}}
+
+	for resourceType, opts := range ClusterResources {
+		from, to := opts.extractTenantResources(a), opts.extractTenantResources(b)
+		if resourceType == "namespaces" {
+			from, to = trimTenantPrefix(from, a.GetName()), trimTenantPrefix(to, b.GetName())
+		}
+		d.Categories[resourceType] = diffSets(from, to)
+	}
+	d.Categories[accessControlCategory] = diffSets(memberKeys(a), memberKeys(b))
+
+	return d
+}
+
+func diffSets(from, to []string) categoryDiff {
+	fromSet, toSet := sets.New(from...), sets.New(to...)
+	return categoryDiff{
+		Added:   sets.List(toSet.Difference(fromSet)),
+		Removed: sets.List(fromSet.Difference(toSet)),
+	}
+}
+
+// trimTenantPrefix drops the "<tenant>-" prefix the operator adds to namespace names.
+func trimTenantPrefix(names []string, tenantName string) []string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		out = append(out, strings.TrimPrefix(name, tenantName+"-"))
+	}
+	return out
+}
+
+// memberKeys renders the members of a tenant as "<role> <kind> <name>".
+func memberKeys(u *unstructured.Unstructured) []string {
+	members := extractMembers(u)
+	out := make([]string, 0, len(members))
+	for _, m := range members {
+		out = append(out, fmt.Sprintf("%s %s %s", m.Role, m.Kind, m.Name))
+	}
+	return out
+}
+
+func printTenantDiff(out io.Writer, format string, d tenantDiff) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(d, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(d)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	categories := make([]string, 0, len(d.Categories))
+	for category := range d.Categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", d.From, d.To)
+	differences := false
+	for _, category := range categories {
+		c := d.Categories[category]
+		if len(c.Added) == 0 && len(c.Removed) == 0 {
+			continue
+		}
+		differences = true
+		fmt.Fprintf(&b, "%s:\n", category)
+		for _, name := range c.Removed {
+			fmt.Fprintf(&b, "  - %s\n", name)
+		}
+		for _, name := range c.Added {
+			fmt.Fprintf(&b, "  + %s\n", name)
+		}
+	}
+	if !differences {
+		b.WriteString("No differences.\n")
+	}
+
+	if _, err := io.WriteString(out, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
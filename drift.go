@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// Drift states of a namespace, comparing the Tenant status to the cluster.
+const (
+	driftOrphaned    = "orphaned"
+	driftMissing     = "missing"
+	driftTerminating = "terminating"
+)
+
+// namespaceContentConditions are the namespace conditions that explain why
+// deletion doesn't finish.
+var namespaceContentConditions = []string{
+	"NamespaceDeletionDiscoveryFailure",
+	"NamespaceDeletionGroupVersionParsingFailure",
+	"NamespaceDeletionContentFailure",
+	"NamespaceContentRemaining",
+	"NamespaceFinalizersRemaining",
+}
+
+// namespaceDrift is a namespace whose state doesn't match the Tenant status.
+type namespaceDrift struct {
+	Namespace string `json:"namespace"`
+	State     string `json:"state"`
+	// InStatus and Labelled tell where the namespace was found
+	InStatus bool `json:"inStatus"`
+	Labelled bool `json:"labelled"`
+	// DeletionTimestamp, Finalizers and Messages are set for terminating namespaces
+	DeletionTimestamp *metav1.Time `json:"deletionTimestamp,omitempty"`
+	Finalizers        []string     `json:"finalizers,omitempty"`
+	Messages          []string     `json:"messages,omitempty"`
+}
+
+func newDriftCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "drift <tenant>",
+		Short: "Compare the namespaces in the status of a Tenant with the cluster",
+		Long: `Compare the namespaces in the status of a Tenant with the namespaces
+labelled ` + tenantKey + `=<tenant> in the cluster.
+
+Namespaces are reported as
+  orphaned     labelled for the tenant, but not in its status
+  missing      in the status of the tenant, but not in the cluster
+  terminating  being deleted; the finalizers and the conditions that hold
+               up the deletion are shown`,
+		Example: `  # Show the namespace drift of my-tenant
+  kubectl tenant drift my-tenant
+
+  # Show the drift as JSON
+  kubectl tenant drift my-tenant -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+			if output != "" && output != "json" && output != "yaml" {
+				return fmt.Errorf("unsupported output format %q: must be one of json, yaml", output)
+			}
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			tenant, err := getTenant(cmd.Context(), dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			drift, err := tenantNamespaceDrift(cmd.Context(), dyn, tenant)
+			if err != nil {
+				return err
+			}
+			return printNamespaceDrift(ioStreams.Out, output, tenantName, drift)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json or yaml")
+
+	return cmd
+}
+
+// tenantNamespaceDrift compares the namespaces in the Tenant status with the
+// namespaces labelled for the tenant, sorted by name.
+func tenantNamespaceDrift(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenant *unstructured.Unstructured,
+) ([]namespaceDrift, error) {
+	nsResource := dyn.Resource(ClusterResources["namespaces"].resource)
+
+	labelled, err := nsResource.List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", tenantKey, tenant.GetName()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces of tenant %q: %w", tenant.GetName(), err)
+	}
+
+	inStatus := map[string]bool{}
+	for _, ns := range extractNamespaceNames(tenant) {
+		inStatus[ns] = true
+	}
+
+	var out []namespaceDrift
+	seen := map[string]bool{}
+	for i := range labelled.Items {
+		item := &labelled.Items[i]
+		seen[item.GetName()] = true
+		if d, ok := namespaceDriftOf(item, inStatus[item.GetName()], true); ok {
+			out = append(out, d)
+		}
+	}
+
+	for _, ns := range extractNamespaceNames(tenant) {
+		if seen[ns] {
+			continue
+		}
+		item, err := nsResource.Get(ctx, ns, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			out = append(out, namespaceDrift{Namespace: ns, State: driftMissing, InStatus: true})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get namespace %q: %w", ns, err)
+		}
+		// A namespace in the status that lost its label is fine unless it's being deleted
+		if d, ok := namespaceDriftOf(item, true, false); ok {
+			out = append(out, d)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Namespace < out[j].Namespace
+	})
+	return out, nil
+}
+
+// namespaceDriftOf reports the drift of an existing namespace; false if it has none.
+func namespaceDriftOf(ns *unstructured.Unstructured, inStatus, labelled bool) (namespaceDrift, bool) {
+	d := namespaceDrift{Namespace: ns.GetName(), InStatus: inStatus, Labelled: labelled}
+
+	switch {
+	case ns.GetDeletionTimestamp() != nil:
+		d.State = driftTerminating
+		d.DeletionTimestamp = ns.GetDeletionTimestamp()
+		d.Finalizers = append(d.Finalizers, ns.GetFinalizers()...)
+		specFinalizers, _, _ := unstructured.NestedStringSlice(ns.Object, "spec", "finalizers")
+		d.Finalizers = append(d.Finalizers, specFinalizers...)
+		d.Messages = namespaceDeletionMessages(ns)
+	case !inStatus:
+		d.State = driftOrphaned
+	default:
+		return d, false
+	}
+	return d, true
+}
+
+// namespaceDeletionMessages returns the messages of the true conditions that hold
+// up the deletion of a namespace.
+func namespaceDeletionMessages(ns *unstructured.Unstructured) []string {
+	conditions, _, _ := unstructured.NestedSlice(ns.Object, "status", "conditions")
+
+	var out []string
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || conditionField(cond, "status") != "True" {
+			continue
+		}
+		for _, t := range namespaceContentConditions {
+			if conditionField(cond, "type") == t {
+				out = append(out, conditionField(cond, "message"))
+			}
+		}
+	}
+	return out
+}
+
+func printNamespaceDrift(out io.Writer, format, tenantName string, drift []namespaceDrift) error {
+	if drift == nil {
+		drift = []namespaceDrift{}
+	}
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(drift, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(drift)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	if len(drift) == 0 {
+		if _, err := fmt.Fprintf(out, "Namespaces of tenant %q match its status.\n", tenantName); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAMESPACE\tSTATE\tIN STATUS\tLABELLED\tDETAILS"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, d := range drift {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			d.Namespace, d.State, yesNo(d.InStatus), yesNo(d.Labelled), driftDetails(d)); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// driftDetails summarizes how long a namespace has been terminating and what holds it up.
+func driftDetails(d namespaceDrift) string {
+	if d.State != driftTerminating {
+		return "<none>"
+	}
+	parts := []string{fmt.Sprintf("terminating for %s", translateTimestampSince(*d.DeletionTimestamp))}
+	if len(d.Finalizers) > 0 {
+		parts = append(parts, "finalizers: "+strings.Join(d.Finalizers, ", "))
+	}
+	parts = append(parts, d.Messages...)
+	return strings.Join(parts, "; ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -3,8 +3,11 @@ package e2e
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -12,8 +15,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
 )
 
 const testTenant = "e2e-tenant"
@@ -118,6 +123,20 @@ func runPlugin(args ...string) (stdout, stderr string, err error) {
 	return outBuf.String(), errBuf.String(), err
 }
 
+// tempKubeconfig writes a copy of the current kubeconfig for commands that modify it.
+func tempKubeconfig(t *testing.T) string {
+	t.Helper()
+	config, err := clientcmd.NewDefaultClientConfigLoadingRules().Load()
+	if err != nil {
+		t.Fatalf("failed to load kubeconfig: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := clientcmd.WriteToFile(*config, path); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
 // generateResourceTests creates standard test cases for any resource type
 func generateResourceTests(cfg resourceTestConfig) []struct {
 	name           string
@@ -210,6 +229,9 @@ func runTestCases(t *testing.T, tests []struct {
 				if tt.wantErrContain != "" && !strings.Contains(stderr, tt.wantErrContain) {
 					t.Errorf("stderr %q should contain %q", stderr, tt.wantErrContain)
 				}
+				if tt.wantOutContain != "" && !strings.Contains(stdout, tt.wantOutContain) {
+					t.Errorf("stdout %q should contain %q", stdout, tt.wantOutContain)
+				}
 				return
 			}
 
@@ -397,6 +419,49 @@ func waitForTenantReady(t *testing.T, ctx context.Context) {
 	}
 }
 
+// enableTestSandboxes makes the current user an owner of the test tenant, enables
+// sandboxes and returns the sandbox namespace of the current user once it is deployed.
+func enableTestSandboxes(t *testing.T, ctx context.Context) string {
+	t.Helper()
+	review, err := dyn.Resource(schema.GroupVersionResource{
+		Group: "authentication.k8s.io", Version: "v1", Resource: "selfsubjectreviews",
+	}).Create(ctx, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "authentication.k8s.io/v1",
+		"kind":       "SelfSubjectReview",
+	}}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to look up current user: %v", err)
+	}
+	user, _, _ := unstructured.NestedString(review.Object, "status", "userInfo", "username")
+
+	patch := fmt.Sprintf(
+		`{"spec":{"accessControl":{"owners":{"users":[%q,%q]}},"namespaces":{"sandboxes":{"enabled":true}}}}`,
+		testListSA, user)
+	_, err = dyn.Resource(tenantGVR).Patch(ctx, testTenant, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		t.Fatalf("failed to enable sandboxes: %v", err)
+	}
+
+	timeout := time.After(2 * time.Minute)
+	tick := time.NewTicker(2 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-timeout:
+			t.Fatalf("timeout waiting for the sandbox of %s", user)
+		case <-tick.C:
+			tenant, err := dyn.Resource(tenantGVR).Get(ctx, testTenant, metav1.GetOptions{})
+			if err != nil {
+				continue
+			}
+			sandboxes, _, _ := unstructured.NestedStringMap(tenant.Object, "status", "deployedSandboxes")
+			if sandbox := sandboxes[user]; sandbox != "" {
+				return sandbox
+			}
+		}
+	}
+}
+
 func getServiceAccountToken(t *testing.T) string {
 	t.Helper()
 	cmd := exec.Command("kubectl", "create", "token", "default", "-n", "default")
@@ -486,4 +551,1248 @@ func TestE2E(t *testing.T) {
 		}
 		runTestCases(t, tests)
 	})
+
+	// Test the get members subcommand
+	t.Run("members", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "lists tenant owners",
+				args:           []string{"get", "members", testTenant},
+				wantOutContain: testListSA,
+			},
+			{
+				name:           "output format: json",
+				args:           []string{"get", "members", testTenant, "-o", "json"},
+				wantOutContain: `"role": "owner"`,
+			},
+			{
+				name:           "expand groups without OpenShift",
+				args:           []string{"get", "members", testTenant, "--expand-groups"},
+				wantOutContain: testListSA,
+			},
+			{
+				name:           "error: invalid tenant name",
+				args:           []string{"get", "members", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test the add-member and remove-member subcommands
+	t.Run("membership", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "add member with server dry run",
+				args:           []string{"add-member", testTenant, "--user", "e2e-dry-run", "--role", "viewer", "--dry-run=server"},
+				wantOutContain: "server dry run",
+			},
+			{
+				name:           "add member",
+				args:           []string{"add-member", testTenant, "--user", "e2e-editor", "--role", "editor"},
+				wantOutContain: "+    - e2e-editor",
+			},
+			{
+				name:           "member is listed",
+				args:           []string{"get", "members", testTenant},
+				wantOutContain: "e2e-editor",
+			},
+			{
+				name:           "remove member",
+				args:           []string{"remove-member", testTenant, "--user", "e2e-editor"},
+				wantOutContain: "-    - e2e-editor",
+			},
+			{
+				name:           "error: invalid role",
+				args:           []string{"add-member", testTenant, "--user", "e2e-editor", "--role", "admin"},
+				wantErr:        true,
+				wantErrContain: "invalid role",
+			},
+			{
+				name:           "error: no user or group",
+				args:           []string{"remove-member", testTenant},
+				wantErr:        true,
+				wantErrContain: "--user or --group",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test the allow and disallow subcommands
+	t.Run("allow", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "allow with server dry run",
+				args:           []string{"allow", "storageclasses", testTenant, sc.forbidden, "--dry-run=server"},
+				wantOutContain: "server dry run",
+			},
+			{
+				name:           "allow storage class",
+				args:           []string{"allow", "storageclasses", testTenant, sc.forbidden},
+				wantOutContain: "+  - " + sc.forbidden,
+			},
+			{
+				name:           "allowed storage class is listed",
+				args:           []string{"get", "storageclasses", testTenant, sc.forbidden},
+				wantOutContain: sc.forbidden,
+			},
+			{
+				name:           "disallow storage class",
+				args:           []string{"disallow", "storageclasses", testTenant, sc.forbidden},
+				wantOutContain: "-  - " + sc.forbidden,
+			},
+			{
+				name:           "error: storage class doesn't exist",
+				args:           []string{"allow", "storageclasses", testTenant, sc.invalid},
+				wantErr:        true,
+				wantErrContain: "not found",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test the wait subcommand
+	t.Run("wait", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "namespaces deployed",
+				args:           []string{"wait", testTenant, "--for=namespaces=2"},
+				wantOutContain: "condition met",
+			},
+			{
+				name:           "storage class available",
+				args:           []string{"wait", testTenant, "--for=storageclass=" + sc.allowed[0]},
+				wantOutContain: "condition met",
+			},
+			{
+				name:           "error: timeout prints last status",
+				args:           []string{"wait", testTenant, "--for=storageclass=" + sc.forbidden, "--timeout=3s"},
+				wantErr:        true,
+				wantErrContain: "deployedNamespaces",
+			},
+			{
+				name:           "error: invalid condition",
+				args:           []string{"wait", testTenant, "--for=bogus"},
+				wantErr:        true,
+				wantErrContain: "invalid --for",
+			},
+			{
+				name:           "error: namespace count not a number",
+				args:           []string{"wait", testTenant, "--for=namespaces=abc"},
+				wantErr:        true,
+				wantErrContain: "expects a non-negative integer",
+			},
+			{
+				name:           "error: invalid tenant name",
+				args:           []string{"wait", invalidTenant, "--for=namespaces=1"},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test the status subcommand
+	t.Run("status", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "shows tenant generation",
+				args:           []string{"status", testTenant},
+				wantOutContain: "Observed Generation:",
+			},
+			{
+				name:           "error: invalid tenant name",
+				args:           []string{"status", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test get with several tenants
+	t.Run("multiple tenants", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "all tenants adds tenant column",
+				args:           []string{"get", "storageclasses", "--all-tenants"},
+				wantOutContain: "TENANT",
+			},
+			{
+				name:           "all tenants lists permitted resource",
+				args:           []string{"get", "storageclasses", "--all-tenants"},
+				wantOutContain: sc.allowed[0],
+			},
+			{
+				name:           "output format: json carries tenant annotation",
+				args:           []string{"get", "storageclasses", "--all-tenants", "-o", "json"},
+				wantOutContain: `"stakater.com/tenant": "` + testTenant + `"`,
+			},
+			{
+				name:           "error: one invalid tenant is reported",
+				args:           []string{"get", "storageclasses", testTenant + "," + invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+
+		// The failing tenant must not hide the results of the others
+		stdout, _, _ := runPlugin("get", "storageclasses", testTenant+","+invalidTenant)
+		if !strings.Contains(stdout, sc.allowed[0]) {
+			t.Errorf("stdout %q should contain %q", stdout, sc.allowed[0])
+		}
+	})
+
+	// Test the who-uses subcommand
+	t.Run("who-uses", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		ns := testResources["namespaces"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "tenant permitted to use storage class",
+				args:           []string{"who-uses", "storageclass", sc.allowed[0]},
+				wantOutContain: "status.storageClasses.available",
+			},
+			{
+				name:           "tenant owning namespace",
+				args:           []string{"who-uses", "namespaces", ns.tenantNs1},
+				wantOutContain: testTenant,
+			},
+			{
+				name:           "output format: json",
+				args:           []string{"who-uses", "namespace", ns.tenantNs1, "-o", "json"},
+				wantOutContain: "status.deployedNamespaces",
+			},
+			{
+				name:           "no tenant uses forbidden storage class",
+				args:           []string{"who-uses", "storageclasses", sc.forbidden},
+				wantOutContain: "No tenants found",
+			},
+			{
+				name:           "error: unsupported resource",
+				args:           []string{"who-uses", "pods", "foo"},
+				wantErr:        true,
+				wantErrContain: "unsupported resource type",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test the matrix subcommand
+	t.Run("matrix", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "tenant column",
+				args:           []string{"matrix"},
+				wantOutContain: testTenant,
+			},
+			{
+				name:           "output format: csv",
+				args:           []string{"matrix", "storageclasses", "-o", "csv"},
+				wantOutContain: "storageclasses," + sc.allowed[1] + ",",
+			},
+			{
+				name:           "output format: markdown",
+				args:           []string{"matrix", "-o", "markdown"},
+				wantOutContain: "| RESOURCE | NAME |",
+			},
+			{
+				name:           "unpermitted classes are highlighted",
+				args:           []string{"matrix", "storageclass", "--show-unpermitted"},
+				wantOutContain: "(unpermitted)",
+			},
+			{
+				name:           "error: unsupported output format",
+				args:           []string{"matrix", "-o", "html"},
+				wantErr:        true,
+				wantErrContain: "unsupported output format",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test the diff subcommand
+	t.Run("diff", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "tenant has no differences with itself",
+				args:           []string{"diff", testTenant, testTenant},
+				wantOutContain: "No differences.",
+			},
+			{
+				name:           "output format: json",
+				args:           []string{"diff", testTenant, testTenant, "-o", "json"},
+				wantOutContain: `"categories"`,
+			},
+			{
+				name:           "error: invalid tenant name",
+				args:           []string{"diff", testTenant, invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test label and field selectors on get
+	t.Run("selectors", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		ns := testResources["namespaces"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "field selector on name",
+				args:           []string{"get", "storageclasses", testTenant, "--field-selector", "metadata.name=" + sc.allowed[1]},
+				wantOutContain: sc.allowed[1],
+			},
+			{
+				name:           "label selector on namespaces",
+				args:           []string{"get", "namespaces", testTenant, "-l", "kubernetes.io/metadata.name=" + ns.tenantNs2},
+				wantOutContain: ns.tenantNs2,
+			},
+			{
+				name:           "field selector on namespace phase",
+				args:           []string{"get", "namespaces", testTenant, "--field-selector", "status.phase=Active"},
+				wantOutContain: ns.tenantNs1,
+			},
+			{
+				name:           "error: unsupported field",
+				args:           []string{"get", "storageclasses", testTenant, "--field-selector", "provisioner=foo"},
+				wantErr:        true,
+				wantErrContain: "field label not supported",
+			},
+			{
+				name:           "error: name with selector",
+				args:           []string{"get", "storageclasses", testTenant, sc.allowed[0], "-l", "tier=gold"},
+				wantErr:        true,
+				wantErrContain: "selector",
+			},
+		}
+		runTestCases(t, tests)
+
+		stdout, stderr, err := runPlugin("get", "storageclasses", testTenant,
+			"--field-selector", "metadata.name="+sc.allowed[1])
+		if err != nil {
+			t.Fatalf("unexpected error: %v, stderr: %s", err, stderr)
+		}
+		if strings.Contains(stdout, sc.allowed[0]) {
+			t.Errorf("stdout %q should not contain %q", stdout, sc.allowed[0])
+		}
+	})
+
+	// Test several names, glob patterns and --name-regex on get
+	t.Run("name patterns", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		ns := testResources["namespaces"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "glob on namespaces",
+				args:           []string{"get", "namespaces", testTenant, testTenant + "-*"},
+				wantOutContain: ns.tenantNs2,
+			},
+			{
+				name:           "name regex",
+				args:           []string{"get", "storageclasses", testTenant, "--name-regex", "^" + sc.allowed[1] + "$"},
+				wantOutContain: sc.allowed[1],
+			},
+			{
+				name:           "several names",
+				args:           []string{"get", "storageclasses", testTenant, sc.allowed[0], sc.allowed[1]},
+				wantOutContain: sc.allowed[1],
+			},
+			{
+				name:           "error: name not permitted among several",
+				args:           []string{"get", "storageclasses", testTenant, sc.allowed[0], sc.forbidden},
+				wantErr:        true,
+				wantErrContain: "is not permitted",
+			},
+			{
+				name:           "error: invalid pattern",
+				args:           []string{"get", "storageclasses", testTenant, "sc-["},
+				wantErr:        true,
+				wantErrContain: "invalid name pattern",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// sandbox is the sandbox namespace of the current user, deployed by the
+	// namespace types tests
+	var sandbox string
+
+	// Test namespace types and sandbox filters
+	t.Run("namespace types", func(t *testing.T) {
+		ns := testResources["namespaces"]
+		sandbox = enableTestSandboxes(t, context.Background())
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "type and owner columns",
+				args:           []string{"get", "namespaces", testTenant},
+				wantOutContain: "OWNER",
+			},
+			{
+				name:           "exclude sandboxes",
+				args:           []string{"get", "namespaces", testTenant, "--exclude-sandboxes"},
+				wantOutContain: ns.tenantNs1,
+			},
+			{
+				name:           "only my sandboxes",
+				args:           []string{"get", "namespaces", testTenant, "--mine"},
+				wantOutContain: sandbox,
+			},
+			{
+				name:           "error: sandboxes only and excluded",
+				args:           []string{"get", "namespaces", testTenant, "--sandboxes-only", "--exclude-sandboxes"},
+				wantErr:        true,
+				wantErrContain: "none of the others can be",
+			},
+		}
+		runTestCases(t, tests)
+
+		stdout, stderr, err := runPlugin("get", "namespaces", testTenant, "--sandboxes-only")
+		if err != nil {
+			t.Fatalf("unexpected error: %v, stderr: %s", err, stderr)
+		}
+		if strings.Contains(stdout, ns.tenantNs1) {
+			t.Errorf("stdout %q should not contain %q", stdout, ns.tenantNs1)
+		}
+		if !strings.Contains(stdout, sandbox) {
+			t.Errorf("stdout %q should contain %q", stdout, sandbox)
+		}
+	})
+
+	// Test sandbox lifecycle commands
+	t.Run("sandbox", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "list sandboxes",
+				args:           []string{"sandbox", "list", testTenant},
+				wantOutContain: sandbox,
+			},
+			{
+				name:           "create with server dry run",
+				args:           []string{"sandbox", "create", testTenant, "--dry-run=server"},
+				wantOutContain: "(server dry run)",
+			},
+			{
+				name:           "delete with client dry run",
+				args:           []string{"sandbox", "delete", testTenant, "--all", "--dry-run=client"},
+				wantOutContain: "(dry run)",
+			},
+			{
+				name:           "error: switch without wait",
+				args:           []string{"sandbox", "create", testTenant, "--switch", "--wait=false"},
+				wantErr:        true,
+				wantErrContain: "--switch requires --wait",
+			},
+			{
+				name:           "error: invalid tenant",
+				args:           []string{"sandbox", "create", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test switching the kubeconfig namespace
+	t.Run("ns", func(t *testing.T) {
+		ns := testResources["namespaces"]
+		kubeconfig := tempKubeconfig(t)
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "list namespaces without a terminal",
+				args:           []string{"ns", testTenant, "--kubeconfig", kubeconfig},
+				wantOutContain: ns.tenantNs2,
+			},
+			{
+				name: "switch with tenant prefix shorthand",
+				args: []string{"ns", testTenant, strings.TrimPrefix(ns.tenantNs1, testTenant+"-"),
+					"--kubeconfig", kubeconfig},
+				wantOutContain: ns.tenantNs1,
+			},
+			{
+				name:           "error: namespace of another tenant",
+				args:           []string{"ns", testTenant, ns.forbidden, "--kubeconfig", kubeconfig},
+				wantErr:        true,
+				wantErrContain: "is not a namespace of tenant",
+			},
+		}
+		runTestCases(t, tests)
+
+		config, err := clientcmd.LoadFromFile(kubeconfig)
+		if err != nil {
+			t.Fatalf("failed to load kubeconfig: %v", err)
+		}
+		if got := config.Contexts[config.CurrentContext].Namespace; got != ns.tenantNs1 {
+			t.Errorf("context namespace = %q, want %q", got, ns.tenantNs1)
+		}
+	})
+
+	// Test generating a kubeconfig with a context per tenant namespace
+	t.Run("kubeconfig", func(t *testing.T) {
+		ns := testResources["namespaces"]
+		kubeconfig := tempKubeconfig(t)
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "print kubeconfig",
+				args:           []string{"kubeconfig", testTenant, "--kubeconfig", kubeconfig},
+				wantOutContain: "namespace: " + ns.tenantNs1,
+			},
+			{
+				name: "merge contexts",
+				args: []string{"kubeconfig", testTenant, "--merge", "--context-template", "e2e-{{.Namespace}}",
+					"--kubeconfig", kubeconfig},
+				wantOutContain: "e2e-" + ns.tenantNs2,
+			},
+			{
+				name: "merge again is unchanged",
+				args: []string{"kubeconfig", testTenant, "--merge", "--context-template", "e2e-{{.Namespace}}",
+					"--kubeconfig", kubeconfig},
+				wantOutContain: "up to date",
+			},
+			{
+				name: "error: invalid template",
+				args: []string{"kubeconfig", testTenant, "--context-template", "{{.Namespace",
+					"--kubeconfig", kubeconfig},
+				wantErr:        true,
+				wantErrContain: "invalid --context-template",
+			},
+		}
+		runTestCases(t, tests)
+
+		config, err := clientcmd.LoadFromFile(kubeconfig)
+		if err != nil {
+			t.Fatalf("failed to load kubeconfig: %v", err)
+		}
+		if got := config.Contexts["e2e-"+ns.tenantNs1]; got == nil || got.Namespace != ns.tenantNs1 {
+			t.Errorf("context %q = %+v, want namespace %q", "e2e-"+ns.tenantNs1, got, ns.tenantNs1)
+		}
+
+		// Renaming the contexts prunes the old ones, except the current context
+		current := "e2e-" + ns.tenantNs1
+		config.CurrentContext = current
+		if err := clientcmd.WriteToFile(*config, kubeconfig); err != nil {
+			t.Fatalf("failed to write kubeconfig: %v", err)
+		}
+		runTestCases(t, []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name: "merge keeps the current context",
+				args: []string{"kubeconfig", testTenant, "--merge", "--context-template", "e2e-new-{{.Namespace}}",
+					"--kubeconfig", kubeconfig},
+				wantOutContain: fmt.Sprintf("Context %q kept", current),
+			},
+		})
+
+		config, err = clientcmd.LoadFromFile(kubeconfig)
+		if err != nil {
+			t.Fatalf("failed to load kubeconfig: %v", err)
+		}
+		if config.CurrentContext != current || config.Contexts[current] == nil {
+			t.Errorf("current context %q was pruned", current)
+		}
+		if stale := "e2e-" + ns.tenantNs2; config.Contexts[stale] != nil {
+			t.Errorf("stale context %q was not pruned", stale)
+		}
+	})
+
+	// Test quota usage report
+	t.Run("quota usage", func(t *testing.T) {
+		ns := testResources["namespaces"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "usage per resource",
+				args:           []string{"quota", "usage", testTenant},
+				wantOutContain: "requests.cpu",
+			},
+			{
+				name:           "usage per namespace",
+				args:           []string{"quota", "usage", testTenant, "-o", "wide"},
+				wantOutContain: ns.tenantNs1,
+			},
+			{
+				name:           "usage as json",
+				args:           []string{"quota", "usage", testTenant, "-o", "json"},
+				wantOutContain: `"hard": "8Gi"`,
+			},
+			{
+				name:           "error: invalid tenant",
+				args:           []string{"quota", "usage", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test quota fit check of manifests
+	t.Run("quota check", func(t *testing.T) {
+		ns := testResources["namespaces"]
+		dir := t.TempDir()
+		deployment := func(replicas int, cpu string) string {
+			return fmt.Sprintf(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: e2e-app
+  namespace: %s
+spec:
+  replicas: %d
+  selector:
+    matchLabels: {app: e2e-app}
+  template:
+    metadata:
+      labels: {app: e2e-app}
+    spec:
+      containers:
+      - name: app
+        image: nginx
+        resources:
+          requests: {cpu: %s, memory: 64Mi}
+`, ns.tenantNs1, replicas, cpu)
+		}
+		small := filepath.Join(dir, "small.yaml")
+		large := filepath.Join(dir, "large.yaml")
+		if err := os.WriteFile(small, []byte(deployment(1, "100m")), 0o600); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+		if err := os.WriteFile(large, []byte(deployment(10, "1")), 0o600); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "manifests fit",
+				args:           []string{"quota", "check", testTenant, "-f", small},
+				wantOutContain: "requests.cpu",
+			},
+			{
+				name:           "error: manifests exceed quota",
+				args:           []string{"quota", "check", testTenant, "-f", large},
+				wantErr:        true,
+				wantErrContain: "exceed quota",
+			},
+			{
+				name:           "error: missing filename",
+				args:           []string{"quota", "check", testTenant},
+				wantErr:        true,
+				wantErrContain: "filename",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test quota details with get -o wide and describe
+	t.Run("quota details", func(t *testing.T) {
+		ns := testResources["namespaces"]
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "get quotas wide",
+				args:           []string{"get", "quotas", testTenant, "-o", "wide"},
+				wantOutContain: "limits.memory=8Gi",
+			},
+			{
+				name:           "get quotas wide for all tenants",
+				args:           []string{"get", "quotas", "--all-tenants", "-o", "wide"},
+				wantOutContain: "limits.memory=8Gi",
+			},
+			{
+				name:           "describe quota",
+				args:           []string{"describe", "quota", testTenant},
+				wantOutContain: "Hard Limits:",
+			},
+			{
+				name:           "describe quota lists namespaces",
+				args:           []string{"describe", "quota", testTenant},
+				wantOutContain: ns.tenantNs1,
+			},
+			{
+				name:           "error: invalid tenant",
+				args:           []string{"describe", "quota", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test top; the cluster may not serve the metrics API
+	t.Run("top", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "error: invalid tenant",
+				args:           []string{"top", "namespaces", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+			{
+				name:    "error: missing tenant",
+				args:    []string{"top", "pods"},
+				wantErr: true,
+			},
+		}
+		runTestCases(t, tests)
+
+		out, stderr, err := runPlugin("top", "namespaces", testTenant)
+		if err != nil {
+			t.Fatalf("unexpected error: %v, stderr: %s", err, stderr)
+		}
+		// Every tenant namespace has a row, even without running pods
+		ns := testResources["namespaces"]
+		for _, want := range []string{"TOTAL", ns.tenantNs1, ns.tenantNs2} {
+			if !strings.Contains(out, want) {
+				t.Errorf("top namespaces output has no %s row:\n%s", want, out)
+			}
+		}
+	})
+
+	// Test audit of the classes in use
+	t.Run("audit", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "no violations",
+				args:           []string{"audit", testTenant},
+				wantOutContain: "No objects use classes",
+			},
+			{
+				name:           "sarif output",
+				args:           []string{"audit", testTenant, "-o", "sarif"},
+				wantOutContain: `"version": "2.1.0"`,
+			},
+			{
+				name:           "error: unsupported output",
+				args:           []string{"audit", testTenant, "-o", "yaml"},
+				wantErr:        true,
+				wantErrContain: "unsupported output format",
+			},
+			{
+				name:           "error: invalid tenant",
+				args:           []string{"audit", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test drift between the tenant status and the cluster namespaces
+	t.Run("drift", func(t *testing.T) {
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "error: unsupported output",
+				args:           []string{"drift", testTenant, "-o", "sarif"},
+				wantErr:        true,
+				wantErrContain: "unsupported output format",
+			},
+			{
+				name:           "error: invalid tenant",
+				args:           []string{"drift", invalidTenant},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+
+		t.Run("json output", func(t *testing.T) {
+			stdout, stderr, err := runPlugin("drift", testTenant, "-o", "json")
+			if err != nil {
+				t.Fatalf("unexpected error: %v, stderr: %s", err, stderr)
+			}
+			var drift []struct {
+				Namespace string `json:"namespace"`
+				State     string `json:"state"`
+			}
+			if err := json.Unmarshal([]byte(stdout), &drift); err != nil {
+				t.Fatalf("failed to parse output: %v\n%s", err, stdout)
+			}
+			ns := testResources["namespaces"]
+			for _, d := range drift {
+				if d.State == "missing" && (d.Namespace == ns.tenantNs1 || d.Namespace == ns.tenantNs2) {
+					t.Errorf("existing tenant namespace %s reported as missing", d.Namespace)
+				}
+			}
+		})
+	})
+
+	// Test offline evaluation of Tenant manifests
+	t.Run("offline", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		manifest := filepath.Join(t.TempDir(), "tenant.yaml")
+		tenant := fmt.Sprintf(`apiVersion: tenantoperator.stakater.com/v1beta3
+kind: Tenant
+metadata:
+  name: e2e-offline
+spec:
+  namespaces:
+    withTenantPrefix: [review]
+  storageClasses:
+    allowed: [%s, e2e-sc-not-in-cluster]
+`, sc.allowed[0])
+		if err := os.WriteFile(manifest, []byte(tenant), 0o600); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "get without resolving",
+				args:           []string{"get", "storageclasses", "e2e-offline", "-f", manifest},
+				wantOutContain: "e2e-sc-not-in-cluster",
+			},
+			{
+				name:           "get resolved against the cluster",
+				args:           []string{"get", "storageclasses", "e2e-offline", "-f", manifest, "--resolve", "cluster"},
+				wantOutContain: sc.allowed[0],
+			},
+			{
+				name: "error: permitted name not in the cluster among several",
+				args: []string{"get", "storageclasses", "e2e-offline", sc.allowed[0], "e2e-sc-not-in-cluster",
+					"-f", manifest, "--resolve", "cluster"},
+				wantErr:        true,
+				wantErrContain: "not found",
+			},
+			{
+				name:           "namespaces derived from the spec",
+				args:           []string{"get", "namespaces", "e2e-offline", "-f", manifest},
+				wantOutContain: "e2e-offline-review",
+			},
+			{
+				name:           "matrix of the manifests",
+				args:           []string{"matrix", "storageclasses", "-f", manifest},
+				wantOutContain: "e2e-offline",
+			},
+			{
+				name:           "error: tenant not in the manifests",
+				args:           []string{"get", "storageclasses", testTenant, "-f", manifest},
+				wantErr:        true,
+				wantErrContain: "not found",
+			},
+			{
+				name:           "error: resolve without filename",
+				args:           []string{"get", "storageclasses", testTenant, "--resolve", "cluster"},
+				wantErr:        true,
+				wantErrContain: "--resolve can only be used with -f",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test validation of Tenant manifests
+	t.Run("validate", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		ns := testResources["namespaces"]
+		dir := t.TempDir()
+		tenant := func(name, extra string) string {
+			return fmt.Sprintf(`apiVersion: tenantoperator.stakater.com/v1beta3
+kind: Tenant
+metadata:
+  name: %s
+spec:
+  storageClasses:
+    allowed: [%s]
+%s`, name, sc.allowed[0], extra)
+		}
+		manifests := map[string]string{
+			"valid.yaml":     tenant("e2e-validate", ""),
+			"unknown.yaml":   tenant("e2e-validate-unknown", "  unknownField: true\n"),
+			"reference.yaml": tenant("e2e-validate-reference", "  quota: e2e-quota-does-not-exist\n"),
+			"collision.yaml": tenant("e2e-validate-collision", "  namespaces:\n    withoutTenantPrefix: ["+ns.tenantNs1+"]\n"),
+		}
+		for file, content := range manifests {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+		}
+
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "valid manifest",
+				args:           []string{"validate", "-f", filepath.Join(dir, "valid.yaml")},
+				wantOutContain: "No problems found",
+			},
+			{
+				name:           "error: unknown field",
+				args:           []string{"validate", "-f", filepath.Join(dir, "unknown.yaml")},
+				wantErr:        true,
+				wantErrContain: "problems in the Tenant manifests",
+				wantOutContain: "unknownField",
+			},
+			{
+				name:           "error: missing quota",
+				args:           []string{"validate", "-f", filepath.Join(dir, "reference.yaml")},
+				wantErr:        true,
+				wantErrContain: "problems in the Tenant manifests",
+				wantOutContain: `Quota "e2e-quota-does-not-exist" not found`,
+			},
+			{
+				name:           "error: namespace of another tenant",
+				args:           []string{"validate", "-f", filepath.Join(dir, "collision.yaml")},
+				wantErr:        true,
+				wantErrContain: "problems in the Tenant manifests",
+				wantOutContain: fmt.Sprintf("namespace %q also belongs to tenant %q", ns.tenantNs1, testTenant),
+			},
+			{
+				name:           "error: missing filename",
+				args:           []string{"validate"},
+				wantErr:        true,
+				wantErrContain: "filename",
+			},
+		}
+		runTestCases(t, tests)
+
+		t.Run("output format: json", func(t *testing.T) {
+			stdout, _, err := runPlugin("validate", "-f", filepath.Join(dir, "reference.yaml"), "-o", "json")
+			if err == nil {
+				t.Fatalf("expected error, got success. stdout: %s", stdout)
+			}
+			var findings []struct {
+				Tenant string `json:"tenant"`
+				Check  string `json:"check"`
+				Field  string `json:"field"`
+			}
+			if err := json.Unmarshal([]byte(stdout), &findings); err != nil {
+				t.Fatalf("failed to parse output: %v\n%s", err, stdout)
+			}
+			found := false
+			for _, f := range findings {
+				if f.Tenant == "e2e-validate-reference" && f.Check == "reference" && f.Field == "spec.quota" {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("expected a reference finding for spec.quota, got %+v", findings)
+			}
+		})
+	})
+
+	// Test guarded apply of workload manifests
+	t.Run("apply", func(t *testing.T) {
+		sc := testResources["storageclasses"]
+		ns := testResources["namespaces"]
+		dir := t.TempDir()
+		pvc := func(namespace, class string) string {
+			return fmt.Sprintf(`apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: e2e-apply
+  namespace: %s
+spec:
+  storageClassName: %s
+  accessModes: [ReadWriteOnce]
+  resources:
+    requests: {storage: 1Gi}
+`, namespace, class)
+		}
+		statefulSet := fmt.Sprintf(`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: e2e-apply
+  namespace: %s
+spec:
+  serviceName: e2e-apply
+  selector:
+    matchLabels: {app: e2e-apply}
+  template:
+    metadata:
+      labels: {app: e2e-apply}
+    spec:
+      containers:
+        - name: app
+          image: busybox
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+      spec:
+        storageClassName: %s
+        accessModes: [ReadWriteOnce]
+        resources:
+          requests: {storage: 1Gi}
+`, ns.tenantNs1, sc.forbidden)
+		manifests := map[string]string{
+			"allowed.yaml":     pvc(ns.tenantNs1, sc.allowed[0]),
+			"namespace.yaml":   pvc("default", sc.allowed[0]),
+			"class.yaml":       pvc(ns.tenantNs1, sc.forbidden),
+			"statefulset.yaml": statefulSet,
+		}
+		for file, content := range manifests {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o600); err != nil {
+				t.Fatalf("failed to write manifest: %v", err)
+			}
+		}
+
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "server dry run",
+				args:           []string{"apply", testTenant, "-f", filepath.Join(dir, "allowed.yaml"), "--dry-run=server"},
+				wantOutContain: "persistentvolumeclaims/e2e-apply serverside-applied (server dry run)",
+			},
+			{
+				name:           "error: namespace outside the tenant",
+				args:           []string{"apply", testTenant, "-f", filepath.Join(dir, "namespace.yaml"), "--dry-run=client"},
+				wantErr:        true,
+				wantErrContain: "nothing was applied",
+				wantOutContain: fmt.Sprintf(`namespace "default" is not a namespace of tenant %q`, testTenant),
+			},
+			{
+				name:           "error: class not permitted",
+				args:           []string{"apply", testTenant, "-f", filepath.Join(dir, "class.yaml"), "--dry-run=client"},
+				wantErr:        true,
+				wantErrContain: "nothing was applied",
+				wantOutContain: fmt.Sprintf(`spec.storageClassName %q is not permitted`, sc.forbidden),
+			},
+			{
+				name:           "error: volume claim template class not permitted",
+				args:           []string{"apply", testTenant, "-f", filepath.Join(dir, "statefulset.yaml"), "--dry-run=client"},
+				wantErr:        true,
+				wantErrContain: "nothing was applied",
+				wantOutContain: fmt.Sprintf(`spec.volumeClaimTemplates[0].spec.storageClassName %q is not permitted`, sc.forbidden),
+			},
+			{
+				name:           "error: missing filename",
+				args:           []string{"apply", testTenant},
+				wantErr:        true,
+				wantErrContain: "filename",
+			},
+		}
+		runTestCases(t, tests)
+	})
+
+	// Test export of a tenant as a manifest bundle
+	t.Run("export", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "bundle")
+		// A file from an earlier export is replaced, other files are kept
+		stale, other := filepath.Join(dir, "09-tenant.yaml"), filepath.Join(dir, "notes.yaml")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("failed to create output directory: %v", err)
+		}
+		for _, path := range []string{stale, other} {
+			if err := os.WriteFile(path, []byte("kind: Stale\n"), 0o600); err != nil {
+				t.Fatalf("failed to write %s: %v", path, err)
+			}
+		}
+		tests := []struct {
+			name           string
+			args           []string
+			wantErr        bool
+			wantErrContain string
+			wantOutContain string
+		}{
+			{
+				name:           "export bundle",
+				args:           []string{"export", testTenant, "-o", dir},
+				wantOutContain: "kustomization.yaml",
+			},
+			{
+				name:           "error: missing output directory",
+				args:           []string{"export", testTenant},
+				wantErr:        true,
+				wantErrContain: "output directory is required",
+			},
+			{
+				name:           "error: invalid tenant",
+				args:           []string{"export", invalidTenant, "-o", dir},
+				wantErr:        true,
+				wantErrContain: invalidTenant,
+			},
+		}
+		runTestCases(t, tests)
+
+		if _, err := os.Stat(stale); !os.IsNotExist(err) {
+			t.Errorf("file %s of an earlier export was not removed", stale)
+		}
+		if _, err := os.Stat(other); err != nil {
+			t.Errorf("unrelated file %s was removed: %v", other, err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, "kustomization.yaml"))
+		if err != nil {
+			t.Fatalf("failed to read kustomization: %v", err)
+		}
+		var kustomization struct {
+			Resources []string `json:"resources"`
+		}
+		if err := yaml.Unmarshal(data, &kustomization); err != nil {
+			t.Fatalf("failed to parse kustomization: %v", err)
+		}
+		// readExported reads the file the kustomization lists for a kind
+		readExported := func(suffix string) string {
+			t.Helper()
+			for _, name := range kustomization.Resources {
+				if strings.HasSuffix(name, suffix) {
+					content, err := os.ReadFile(filepath.Join(dir, name))
+					if err != nil {
+						t.Fatalf("failed to read %s: %v", name, err)
+					}
+					return string(content)
+				}
+			}
+			t.Fatalf("kustomization doesn't list a file ending in %s:\n%s", suffix, data)
+			return ""
+		}
+
+		tenant := readExported("-tenant.yaml")
+		if strings.Contains(tenant, "status:") || strings.Contains(tenant, "managedFields") {
+			t.Errorf("exported Tenant still has status or managed fields:\n%s", tenant)
+		}
+		sc := testResources["storageclasses"]
+		if !strings.Contains(readExported("-storageclasses.yaml"), "name: "+sc.allowed[0]) {
+			t.Errorf("exported StorageClasses don't include %s", sc.allowed[0])
+		}
+		if quota := readExported("-quota.yaml"); !strings.Contains(quota, "kind: Quota") {
+			t.Errorf("exported Quota is not a Quota:\n%s", quota)
+		}
+	})
 }
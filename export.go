@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// exportedResources are the permitted cluster objects written by export, in the
+// order they are applied.
+var exportedResources = []string{"storageclasses", "ingressclasses", "priorityclasses"}
+
+// exportFile is one file of an exported bundle.
+type exportFile struct {
+	name string
+	objs []*unstructured.Unstructured
+}
+
+func newExportCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "export <tenant> -o <directory>",
+		Short: "Export a Tenant with its quota and permitted classes as manifests",
+		Long: `Export a Tenant with its quota and permitted classes as manifests.
+
+The directory receives a YAML file per kind, numbered in the order they must be
+applied: the StorageClasses, IngressClasses and PriorityClasses available to the
+tenant, its Quota, and the Tenant CR last. A kustomization.yaml lists them, so
+the bundle can be applied to another cluster with 'kubectl apply -k'.
+
+Status, managed fields and the other fields the API server maintains are left
+out. Files written by an earlier export to the same directory are replaced;
+other files in it are left alone.`,
+		Example: `  # Export my-tenant for a migration
+  kubectl tenant export my-tenant -o backup/my-tenant
+
+  # Restore it on another cluster
+  kubectl apply -k backup/my-tenant`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+			if outputDir == "" {
+				return errors.New("an output directory is required: -o <directory>")
+			}
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			tenant, err := getTenant(ctx, dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			var files []exportFile
+			for _, resourceType := range exportedResources {
+				items, err := resolveTenantResources(ctx, dyn, tenant, ClusterResources[resourceType], &listFilter{})
+				if err != nil {
+					return err
+				}
+				files = append(files, exportFile{name: resourceType, objs: items})
+			}
+
+			quota, err := getTenantQuota(ctx, dyn, tenant)
+			if err != nil {
+				return err
+			}
+			// An empty quota file still removes the one of an earlier export
+			quotaFile := exportFile{name: "quota"}
+			if quota != nil {
+				quotaFile.objs = []*unstructured.Unstructured{quota}
+			}
+			files = append(files, quotaFile)
+			files = append(files, exportFile{name: "tenant", objs: []*unstructured.Unstructured{tenant}})
+
+			written, err := writeExport(outputDir, files)
+			if err != nil {
+				return err
+			}
+			var b strings.Builder
+			for _, path := range written {
+				fmt.Fprintf(&b, "Wrote %s\n", path)
+			}
+			if _, err := fmt.Fprint(ioStreams.Out, b.String()); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputDir, "output", "o", "", "Directory to write the manifests to")
+
+	return cmd
+}
+
+// writeExport writes the non-empty files, numbered in order, and a kustomization
+// listing them, and returns the paths written.
+func writeExport(dir string, files []exportFile) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+	if err := removeExport(dir, files); err != nil {
+		return nil, err
+	}
+
+	var resources, written []string
+	for _, f := range files {
+		if len(f.objs) == 0 {
+			continue
+		}
+		var b bytes.Buffer
+		for i, obj := range f.objs {
+			data, err := yaml.Marshal(stripServerFields(obj).Object)
+			if err != nil {
+				return nil, fmt.Errorf("marshal %s %q: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			if i > 0 {
+				b.WriteString("---\n")
+			}
+			b.Write(data)
+		}
+
+		name := fmt.Sprintf("%02d-%s.yaml", len(resources)+1, f.name)
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+			return nil, err
+		}
+		resources = append(resources, name)
+		written = append(written, path)
+	}
+
+	kustomization, err := yaml.Marshal(map[string]interface{}{
+		"apiVersion": "kustomize.config.k8s.io/v1beta1",
+		"kind":       "Kustomization",
+		"resources":  resources,
+	})
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, "kustomization.yaml")
+	if err := os.WriteFile(path, kustomization, 0o644); err != nil {
+		return nil, err
+	}
+	return append(written, path), nil
+}
+
+// removeExport removes the numbered files an earlier export wrote to dir, so
+// that files of kinds the tenant no longer has don't linger next to the
+// kustomization.
+func removeExport(dir string, files []exportFile) error {
+	for _, f := range files {
+		stale, err := filepath.Glob(filepath.Join(dir, "[0-9][0-9]-"+f.name+".yaml"))
+		if err != nil {
+			return err
+		}
+		for _, path := range stale {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("remove earlier export: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic"
+)
+
+// listFilter narrows the resources permitted for a tenant.
+type listFilter struct {
+	labelSelector string
+	fieldSelector string
+	nameRegex     string
+
+	// names and patterns are the resource names and glob patterns given as arguments.
+	names    []string
+	patterns []string
+	// requirePermitted makes explicit names that aren't permitted or don't
+	// exist an error instead of being skipped.
+	requirePermitted bool
+	// namespaces narrows namespace listings by type and owner.
+	namespaces namespaceFilter
+
+	labels labels.Selector
+	fields fields.Selector
+	regex  *regexp.Regexp
+}
+
+func (f *listFilter) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&f.labelSelector, "selector", "l", "",
+		"Selector (label query) to filter on, supports '=', '==', '!=', 'in', 'notin'.(e.g. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&f.fieldSelector, "field-selector", "",
+		"Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&f.nameRegex, "name-regex", "", "Regular expression the resource names must match")
+}
+
+// setNames sorts resource name arguments into explicit names and glob patterns.
+func (f *listFilter) setNames(args []string) {
+	f.names, f.patterns = nil, nil
+	for _, arg := range args {
+		if strings.ContainsAny(arg, "*?[") {
+			f.patterns = append(f.patterns, arg)
+		} else {
+			f.names = append(f.names, arg)
+		}
+	}
+}
+
+// singleName returns the resource name when exactly one plain name and no pattern was given.
+func (f *listFilter) singleName() (string, bool) {
+	if len(f.names) == 1 && len(f.patterns) == 0 && f.nameRegex == "" && !f.namespaces.active() {
+		return f.names[0], true
+	}
+	return "", false
+}
+
+// complete parses the selectors and checks that the field selector only uses
+// fields supported for the resource, like the API server would.
+func (f *listFilter) complete(opts getOptions) error {
+	if len(f.names) > 0 && f.hasSelectors() {
+		return fmt.Errorf("name cannot be provided when a selector is specified")
+	}
+	for _, p := range f.patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid name pattern %q: %w", p, err)
+		}
+	}
+
+	var err error
+	if f.nameRegex != "" {
+		if f.regex, err = regexp.Compile(f.nameRegex); err != nil {
+			return fmt.Errorf("invalid --name-regex %q: %w", f.nameRegex, err)
+		}
+	}
+	if f.labels, err = labels.Parse(f.labelSelector); err != nil {
+		return fmt.Errorf("invalid label selector %q: %w", f.labelSelector, err)
+	}
+	if f.fields, err = fields.ParseSelector(f.fieldSelector); err != nil {
+		return fmt.Errorf("invalid field selector %q: %w", f.fieldSelector, err)
+	}
+	for _, r := range f.fields.Requirements() {
+		if !slices.Contains(selectableFields(opts), r.Field) {
+			return fmt.Errorf("field label not supported: %s", r.Field)
+		}
+	}
+	return nil
+}
+
+// filterNames narrows the names permitted for a tenant to the requested names,
+// patterns and regular expression.
+func (f *listFilter) filterNames(resourceType, tenantName string, permitted []string) ([]string, error) {
+	if len(f.names) == 0 && len(f.patterns) == 0 && f.regex == nil {
+		return permitted, nil
+	}
+
+	if f.requirePermitted {
+		for _, name := range f.names {
+			if !slices.Contains(permitted, name) {
+				return nil, fmt.Errorf("%s %q is not permitted for tenant %q", resourceType, name, tenantName)
+			}
+		}
+	}
+
+	var out []string
+	for _, name := range permitted {
+		if f.matchesName(name) {
+			out = append(out, name)
+		}
+	}
+	return out, nil
+}
+
+// matchesName reports whether name is one of the given names or patterns, if
+// any, and matches the regular expression, if set.
+func (f *listFilter) matchesName(name string) bool {
+	if f.regex != nil && !f.regex.MatchString(name) {
+		return false
+	}
+	if len(f.names) == 0 && len(f.patterns) == 0 {
+		return true
+	}
+	if slices.Contains(f.names, name) {
+		return true
+	}
+	for _, p := range f.patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *listFilter) hasSelectors() bool {
+	return f.labelSelector != "" || f.fieldSelector != ""
+}
+
+// matches applies the selectors client-side.
+func (f *listFilter) matches(u *unstructured.Unstructured, opts getOptions) bool {
+	if f.labels != nil && !f.labels.Matches(labels.Set(u.GetLabels())) {
+		return false
+	}
+	if f.fields != nil && !f.fields.Matches(objectFieldSet(u, opts)) {
+		return false
+	}
+	return true
+}
+
+// listPermitted lists the resources with the selectors applied server-side and
+// keeps the ones named in permitted. The selectors are applied client-side as
+// well, since the in-memory client of offline mode ignores field selectors.
+func (f *listFilter) listPermitted(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	opts getOptions,
+	permitted []string,
+) ([]*unstructured.Unstructured, error) {
+	list, err := dyn.Resource(opts.resource).List(ctx, metav1.ListOptions{
+		LabelSelector: f.labelSelector,
+		FieldSelector: f.fieldSelector,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*unstructured.Unstructured
+	for i := range list.Items {
+		if slices.Contains(permitted, list.Items[i].GetName()) && f.matches(&list.Items[i], opts) {
+			items = append(items, &list.Items[i])
+		}
+	}
+	return items, nil
+}
+
+// selectableFields are the fields usable in a field selector for the resource.
+func selectableFields(opts getOptions) []string {
+	return append([]string{"metadata.name"}, opts.selectableFields...)
+}
+
+func objectFieldSet(u *unstructured.Unstructured, opts getOptions) fields.Set {
+	set := fields.Set{}
+	for _, field := range selectableFields(opts) {
+		value, _, _ := unstructured.NestedString(u.Object, strings.Split(field, ".")...)
+		set[field] = value
+	}
+	return set
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// currentKubeContext returns the raw kubeconfig and the name of the context in
+// use, which --context overrides.
+func currentKubeContext(configFlags *genericclioptions.ConfigFlags) (clientcmdapi.Config, string, error) {
+	raw, err := configFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return clientcmdapi.Config{}, "", err
+	}
+
+	contextName := raw.CurrentContext
+	if configFlags.Context != nil && *configFlags.Context != "" {
+		contextName = *configFlags.Context
+	}
+	if _, ok := raw.Contexts[contextName]; !ok {
+		return clientcmdapi.Config{}, "", fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+	return raw, contextName, nil
+}
+
+// switchContextNamespace sets the namespace of the kubeconfig context in use, like
+// 'kubectl config set-context --current --namespace', and reports it to out.
+func switchContextNamespace(configFlags *genericclioptions.ConfigFlags, namespace string, out io.Writer) error {
+	raw, contextName, err := currentKubeContext(configFlags)
+	if err != nil {
+		return err
+	}
+	raw.Contexts[contextName].Namespace = namespace
+
+	if err := clientcmd.ModifyConfig(configFlags.ToRawKubeConfigLoader().ConfigAccess(), raw, true); err != nil {
+		return fmt.Errorf("update kubeconfig: %w", err)
+	}
+	if _, err := fmt.Fprintf(out, "Context %q modified to use namespace %q.\n", contextName, namespace); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// contextExtension marks the kubeconfig contexts generated for a tenant, so they
+// can be pruned once the tenant no longer has their namespace.
+const contextExtension = "kubectl-tenant"
+
+// contextTemplateData is available to the --context-template of the kubeconfig command.
+type contextTemplateData struct {
+	Tenant    string
+	Namespace string
+	Cluster   string
+	User      string
+	Context   string
+}
+
+func newKubeconfigCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var merge bool
+	var contextTemplate string
+
+	cmd := &cobra.Command{
+		Use:   "kubeconfig <tenant>",
+		Short: "Generate a kubeconfig context for each namespace of a Tenant",
+		Long: `Generate a kubeconfig context for each namespace of a Tenant.
+
+Every context reuses the cluster and user of the current context and is named
+by --context-template, a Go template with the fields .Tenant, .Namespace,
+.Cluster, .User and .Context (the current context).
+
+By default the kubeconfig is written to stdout. With --merge the contexts are
+added to the current kubeconfig instead, and contexts generated earlier for
+namespaces the tenant no longer has are removed, unless one of them is the
+current context.`,
+		Example: `  # Write a kubeconfig for my-tenant to a file
+  kubectl tenant kubeconfig my-tenant > my-tenant.kubeconfig
+
+  # Add a context per namespace to the current kubeconfig
+  kubectl tenant kubeconfig my-tenant --merge
+
+  # Name the contexts after the namespace only
+  kubectl tenant kubeconfig my-tenant --merge --context-template '{{.Namespace}}'`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+
+			tmpl, err := template.New("context").Option("missingkey=error").Parse(contextTemplate)
+			if err != nil {
+				return fmt.Errorf("invalid --context-template: %w", err)
+			}
+
+			raw, contextName, err := currentKubeContext(configFlags)
+			if err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			tenant, err := getTenant(cmd.Context(), dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			contexts, err := tenantContexts(tmpl, raw, contextName, tenantName, extractNamespaceNames(tenant))
+			if err != nil {
+				return err
+			}
+
+			if merge {
+				return mergeTenantContexts(configFlags, raw, tenantName, contexts, ioStreams.Out)
+			}
+			return writeTenantKubeconfig(ioStreams.Out, raw, contextName, contexts)
+		},
+	}
+
+	cmd.Flags().BoolVar(&merge, "merge", false,
+		"Merge the contexts into the current kubeconfig instead of printing a kubeconfig")
+	cmd.Flags().StringVar(&contextTemplate, "context-template", "{{.Cluster}}/{{.Namespace}}",
+		"Go template for the context names")
+
+	return cmd
+}
+
+// tenantContexts builds a context per namespace from the current context,
+// keyed by the name rendered from tmpl.
+func tenantContexts(
+	tmpl *template.Template,
+	raw clientcmdapi.Config,
+	contextName string,
+	tenantName string,
+	namespaces []string,
+) (map[string]*clientcmdapi.Context, error) {
+	current := raw.Contexts[contextName]
+	marker := &runtime.Unknown{
+		Raw:         []byte(fmt.Sprintf(`{"tenant":%q}`, tenantName)),
+		ContentType: runtime.ContentTypeJSON,
+	}
+
+	out := map[string]*clientcmdapi.Context{}
+	for _, ns := range namespaces {
+		var b strings.Builder
+		err := tmpl.Execute(&b, contextTemplateData{
+			Tenant:    tenantName,
+			Namespace: ns,
+			Cluster:   current.Cluster,
+			User:      current.AuthInfo,
+			Context:   contextName,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("render --context-template for namespace %q: %w", ns, err)
+		}
+		name := b.String()
+		if name == "" {
+			return nil, fmt.Errorf("--context-template renders an empty name for namespace %q", ns)
+		}
+		if _, dup := out[name]; dup {
+			return nil, fmt.Errorf("--context-template renders %q for more than one namespace", name)
+		}
+
+		kubeContext := clientcmdapi.NewContext()
+		kubeContext.Cluster = current.Cluster
+		kubeContext.AuthInfo = current.AuthInfo
+		kubeContext.Namespace = ns
+		kubeContext.Extensions[contextExtension] = marker
+		out[name] = kubeContext
+	}
+	return out, nil
+}
+
+// generatedFor returns the tenant a context was generated for, or "" for other contexts.
+func generatedFor(kubeContext *clientcmdapi.Context) string {
+	ext, ok := kubeContext.Extensions[contextExtension].(*runtime.Unknown)
+	if !ok {
+		return ""
+	}
+	var marker struct {
+		Tenant string `json:"tenant"`
+	}
+	if err := json.Unmarshal(ext.Raw, &marker); err != nil {
+		return ""
+	}
+	return marker.Tenant
+}
+
+func sameContext(a, b *clientcmdapi.Context) bool {
+	return a.Cluster == b.Cluster && a.AuthInfo == b.AuthInfo && a.Namespace == b.Namespace
+}
+
+// writeTenantKubeconfig prints a standalone kubeconfig with the cluster and user of
+// the current context and the tenant contexts.
+func writeTenantKubeconfig(
+	out io.Writer,
+	raw clientcmdapi.Config,
+	contextName string,
+	contexts map[string]*clientcmdapi.Context,
+) error {
+	current := raw.Contexts[contextName]
+
+	config := clientcmdapi.NewConfig()
+	if cluster, ok := raw.Clusters[current.Cluster]; ok {
+		config.Clusters[current.Cluster] = cluster
+	}
+	if user, ok := raw.AuthInfos[current.AuthInfo]; ok {
+		config.AuthInfos[current.AuthInfo] = user
+	}
+	names := make([]string, 0, len(contexts))
+	for name, kubeContext := range contexts {
+		config.Contexts[name] = kubeContext
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		config.CurrentContext = names[0]
+	}
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(data); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// mergeTenantContexts adds the tenant contexts to the kubeconfig and removes the ones
+// generated earlier for namespaces the tenant no longer has. The current context
+// is never removed, so the kubeconfig keeps pointing to a context that exists.
+func mergeTenantContexts(
+	configFlags *genericclioptions.ConfigFlags,
+	raw clientcmdapi.Config,
+	tenantName string,
+	contexts map[string]*clientcmdapi.Context,
+	out io.Writer,
+) error {
+	var b strings.Builder
+	names := make([]string, 0, len(raw.Contexts)+len(contexts))
+	for name := range raw.Contexts {
+		names = append(names, name)
+	}
+	for name := range contexts {
+		if _, ok := raw.Contexts[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		existing, exists := raw.Contexts[name]
+		kubeContext, generated := contexts[name]
+		switch {
+		case generated && !exists:
+			raw.Contexts[name] = kubeContext
+			fmt.Fprintf(&b, "Context %q created.\n", name)
+		case generated && generatedFor(existing) != tenantName:
+			return fmt.Errorf("context %q already exists and was not generated for tenant %q", name, tenantName)
+		case generated && !sameContext(existing, kubeContext):
+			kubeContext.LocationOfOrigin = existing.LocationOfOrigin
+			raw.Contexts[name] = kubeContext
+			fmt.Fprintf(&b, "Context %q modified.\n", name)
+		case !generated && generatedFor(existing) == tenantName && name == raw.CurrentContext:
+			fmt.Fprintf(&b, "Context %q kept, as it is the current context.\n", name)
+		case !generated && generatedFor(existing) == tenantName:
+			delete(raw.Contexts, name)
+			fmt.Fprintf(&b, "Context %q deleted.\n", name)
+		}
+	}
+	if b.Len() == 0 {
+		fmt.Fprintf(&b, "Contexts of tenant %q are up to date.\n", tenantName)
+	}
+
+	if err := clientcmd.ModifyConfig(configFlags.ToRawKubeConfigLoader().ConfigAccess(), raw, true); err != nil {
+		return fmt.Errorf("update kubeconfig: %w", err)
+	}
+	if _, err := io.WriteString(out, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
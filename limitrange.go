@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var limitRangeGVR = schema.GroupVersionResource{Version: "v1", Resource: "limitranges"}
+
+// Limit range states of a tenant namespace compared to its Quota CR.
+const (
+	limitRangeInSync  = "in sync"
+	limitRangeMissing = "missing"
+	limitRangeDiffers = "differs"
+	limitRangeExtra   = "not defined by quota"
+	limitRangeNone    = "<none>"
+)
+
+// namespaceLimitRange is the LimitRange state of one tenant namespace.
+type namespaceLimitRange struct {
+	namespace   string
+	state       string
+	differences []string
+}
+
+// quotaLimitRange reads spec.limitrange of a Quota CR.
+func quotaLimitRange(quota *unstructured.Unstructured) (corev1.LimitRangeSpec, error) {
+	var spec corev1.LimitRangeSpec
+	values, _, err := unstructured.NestedMap(quota.Object, "spec", "limitrange")
+	if err != nil || values == nil {
+		return spec, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(values, &spec); err != nil {
+		return spec, fmt.Errorf("read limit range of quota %q: %w", quota.GetName(), err)
+	}
+	return spec, nil
+}
+
+// limitRangeValues flattens the items of a limit range to "<type> <field> <resource>" keys.
+func limitRangeValues(items []corev1.LimitRangeItem) map[string]resource.Quantity {
+	out := map[string]resource.Quantity{}
+	for _, item := range items {
+		for field, list := range map[string]corev1.ResourceList{
+			"min":                  item.Min,
+			"max":                  item.Max,
+			"default":              item.Default,
+			"defaultRequest":       item.DefaultRequest,
+			"maxLimitRequestRatio": item.MaxLimitRequestRatio,
+		} {
+			for name, q := range list {
+				out[fmt.Sprintf("%s %s %s", item.Type, field, name)] = q
+			}
+		}
+	}
+	return out
+}
+
+// limitRangeDifferences describes how got deviates from want, one line per value.
+func limitRangeDifferences(want, got []corev1.LimitRangeItem) []string {
+	wantValues, gotValues := limitRangeValues(want), limitRangeValues(got)
+
+	var out []string
+	for key, w := range wantValues {
+		g, ok := gotValues[key]
+		switch {
+		case !ok:
+			out = append(out, fmt.Sprintf("%s: not set, expected %s", key, w.String()))
+		case g.Cmp(w) != 0:
+			out = append(out, fmt.Sprintf("%s: %s, expected %s", key, g.String(), w.String()))
+		}
+	}
+	for key, g := range gotValues {
+		if _, ok := wantValues[key]; !ok {
+			out = append(out, fmt.Sprintf("%s: %s, not defined by quota", key, g.String()))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// checkNamespaceLimitRanges compares the LimitRanges of every namespace to the
+// limit range of the Quota CR. The items of several LimitRanges in a namespace
+// are combined, as the API server applies all of them.
+func checkNamespaceLimitRanges(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	namespaces []string,
+	want corev1.LimitRangeSpec,
+) ([]namespaceLimitRange, error) {
+	out := make([]namespaceLimitRange, 0, len(namespaces))
+	for _, ns := range namespaces {
+		list, err := dyn.Resource(limitRangeGVR).Namespace(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list limitranges in namespace %q: %w", ns, err)
+		}
+
+		var got []corev1.LimitRangeItem
+		for _, item := range list.Items {
+			var lr corev1.LimitRange
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &lr); err != nil {
+				return nil, fmt.Errorf("read limitrange %s/%s: %w", ns, item.GetName(), err)
+			}
+			got = append(got, lr.Spec.Limits...)
+		}
+
+		result := namespaceLimitRange{namespace: ns, state: limitRangeInSync}
+		switch {
+		case len(got) == 0 && len(want.Limits) == 0:
+			result.state = limitRangeNone
+		case len(got) == 0:
+			result.state = limitRangeMissing
+		case len(want.Limits) == 0:
+			result.state = limitRangeExtra
+		default:
+			if result.differences = limitRangeDifferences(want.Limits, got); len(result.differences) > 0 {
+				result.state = limitRangeDiffers
+			}
+		}
+		out = append(out, result)
+	}
+	return out, nil
+}
+
+// formatLimitRange renders a limit range on one line, e.g.
+// "Container: default cpu=500m, max cpu=2; Pod: max memory=4Gi".
+func formatLimitRange(spec corev1.LimitRangeSpec) string {
+	var parts []string
+	for _, item := range spec.Limits {
+		values := limitRangeValues([]corev1.LimitRangeItem{item})
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		entries := make([]string, 0, len(keys))
+		for _, key := range keys {
+			// Drop the type from "<type> <field> <resource>", it heads the group
+			_, rest, _ := strings.Cut(key, " ")
+			field, name, _ := strings.Cut(rest, " ")
+			q := values[key]
+			entries = append(entries, fmt.Sprintf("%s %s=%s", field, name, q.String()))
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", item.Type, strings.Join(entries, ", ")))
+	}
+	if len(parts) == 0 {
+		return "<none>"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatResourceList renders a resource list as sorted "name=quantity" pairs.
+func formatResourceList(list corev1.ResourceList) string {
+	if len(list) == 0 {
+		return "<none>"
+	}
+	entries := make([]string, 0, len(list))
+	for _, name := range sortedResourceNames(list) {
+		q := list[name]
+		entries = append(entries, fmt.Sprintf("%s=%s", name, q.String()))
+	}
+	return strings.Join(entries, ", ")
+}
@@ -9,12 +9,14 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -30,6 +32,12 @@ const (
 	PluginName = "kubectl-tenant"
 )
 
+var tenantGVR = schema.GroupVersionResource{
+	Group:    "tenantoperator.stakater.com", // CRD group
+	Version:  "v1beta3",                     // adjust if your CRD version differs
+	Resource: "tenants",
+}
+
 type tenantEntry struct {
 	Name string `json:"name"`
 	Role string `json:"role"`
@@ -43,6 +51,15 @@ type getOptions struct {
 	resource               schema.GroupVersionResource
 	listKind               string
 	extractTenantResources func(*unstructured.Unstructured) []string
+	// statusField is the Tenant field holding status.<field>.available;
+	// empty for namespaces, which are read from the deployed namespace fields.
+	statusField string
+	// allowListField is the Tenant field holding spec.<field>.allowed;
+	// empty for resources without an allow-list.
+	allowListField string
+	// selectableFields are the fields besides metadata.name that the API server
+	// supports in field selectors for the resource.
+	selectableFields []string
 }
 
 var ClusterResources = map[string]getOptions{
@@ -54,6 +71,8 @@ var ClusterResources = map[string]getOptions{
 		},
 		listKind:               "StorageClassList",
 		extractTenantResources: extractStorageClassNames,
+		statusField:            "storageClasses",
+		allowListField:         "storageClasses",
 	},
 	"namespaces": {
 		resource: schema.GroupVersionResource{
@@ -63,6 +82,7 @@ var ClusterResources = map[string]getOptions{
 		},
 		listKind:               "NamespaceList",
 		extractTenantResources: extractNamespaceNames,
+		selectableFields:       []string{"status.phase"},
 	},
 	"ingressclasses": {
 		resource: schema.GroupVersionResource{
@@ -72,6 +92,8 @@ var ClusterResources = map[string]getOptions{
 		},
 		listKind:               "IngressClassList",
 		extractTenantResources: extractIngressClassNames,
+		statusField:            "ingressClasses",
+		allowListField:         "ingressClasses",
 	},
 	"priorityclasses": {
 		resource: schema.GroupVersionResource{
@@ -81,6 +103,8 @@ var ClusterResources = map[string]getOptions{
 		},
 		listKind:               "PriorityClassList",
 		extractTenantResources: extractPodPriorityClassNames,
+		statusField:            "podPriorityClasses",
+		allowListField:         "podPriorityClasses",
 	},
 	"quotas": {
 		resource: schema.GroupVersionResource{
@@ -90,9 +114,22 @@ var ClusterResources = map[string]getOptions{
 		},
 		listKind:               "QuotaList",
 		extractTenantResources: extractQuotaNames,
+		statusField:            "quota",
 	},
 }
 
+// lookupClusterResource finds a ClusterResources entry by its plural name or
+// its singular form (e.g. "storageclass" for "storageclasses").
+func lookupClusterResource(name string) (string, getOptions, bool) {
+	name = strings.ToLower(name)
+	for _, key := range []string{name, name + "s", name + "es"} {
+		if opts, ok := ClusterResources[key]; ok {
+			return key, opts, true
+		}
+	}
+	return "", getOptions{}, false
+}
+
 func main() {
 	cmd := newRootCmd()
 	if err := cmd.Execute(); err != nil {
@@ -131,6 +168,26 @@ cluster-scoped resources based on tenant permissions.`,
 	flags.AddFlags(root.PersistentFlags())
 	root.AddCommand(getCmd)
 	root.AddCommand(listCmd)
+	root.AddCommand(newAddMemberCmd(flags, ioStreams))
+	root.AddCommand(newRemoveMemberCmd(flags, ioStreams))
+	root.AddCommand(newAllowCmd(flags, ioStreams))
+	root.AddCommand(newDisallowCmd(flags, ioStreams))
+	root.AddCommand(newWaitCmd(flags, ioStreams))
+	root.AddCommand(newStatusCmd(flags, ioStreams))
+	root.AddCommand(newWhoUsesCmd(flags, ioStreams))
+	root.AddCommand(newMatrixCmd(flags, ioStreams))
+	root.AddCommand(newDiffCmd(flags, ioStreams))
+	root.AddCommand(newSandboxCmd(flags, ioStreams))
+	root.AddCommand(newNsCmd(flags, ioStreams))
+	root.AddCommand(newKubeconfigCmd(flags, ioStreams))
+	root.AddCommand(newQuotaCmd(flags, ioStreams))
+	root.AddCommand(newDescribeCmd(flags, ioStreams))
+	root.AddCommand(newTopCmd(flags, ioStreams))
+	root.AddCommand(newAuditCmd(flags, ioStreams))
+	root.AddCommand(newDriftCmd(flags, ioStreams))
+	root.AddCommand(newValidateCmd(flags, ioStreams))
+	root.AddCommand(newApplyCmd(flags, ioStreams))
+	root.AddCommand(newExportCmd(flags, ioStreams))
 	root.AddCommand(docsCmd)
 	return root
 }
@@ -148,6 +205,7 @@ permitted for the specified tenant according to the Tenant CR status.`,
 	for resourceName, opts := range ClusterResources {
 		cmd.AddCommand(newGetResourceCmd(resourceName, opts, configFlags, ioStreams))
 	}
+	cmd.AddCommand(newGetMembersCmd(configFlags, ioStreams))
 
 	return cmd
 }
@@ -182,10 +240,14 @@ func newDocsCmd(root *cobra.Command) *cobra.Command {
 
 func newGetResourceCmd(resourceName string, opts getOptions, configFlags *genericclioptions.ConfigFlags,
 	ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var allTenants bool
+	operator := &operatorEndpoint{}
+	offline := &offlineOptions{}
+	filter := &listFilter{}
 	printFlags := get.NewGetPrintFlags()
 
 	cmd := &cobra.Command{
-		Use:   resourceName + " <tenant> [resource-name]",
+		Use:   resourceName + " <tenant>[,<tenant>...] [resource-name|pattern...]",
 		Short: fmt.Sprintf("List %s permitted for a Tenant", resourceName),
 		Long: fmt.Sprintf(`List %s permitted for a Tenant.
 
@@ -193,40 +255,119 @@ This behaves like 'kubectl get %s', but filtered to those listed in
 the Tenant CR status (tenant.tenantoperator.stakater.com).
 
 When a specific resource name is provided, the command validates tenant access
-and passes through to kubectl for native output.`, resourceName, resourceName),
+and passes through to kubectl for native output. Several names can be given, each
+validated the same way, and names may be glob patterns such as 'my-tenant-feature-*'.
+--name-regex filters the names with a regular expression.
+
+Several tenants can be given as a comma-separated list, or all tenants with
+--all-tenants. Results are then merged with a TENANT column; in JSON and YAML
+output each item carries the %s annotation.
+
+Label (-l) and field (--field-selector) selectors are applied to the permitted
+resources, on the server when they may be listed and on the client otherwise.
+
+With -f the Tenants are read from manifests instead of the cluster, and the
+permitted resources are derived from their spec. The names are listed without
+a cluster; --resolve looks the objects up in the cluster or in a directory of
+exported objects.`,
+			resourceName, resourceName, tenantKey),
 		Example: fmt.Sprintf(`  # List %s for my-tenant
   kubectl tenant get %s my-tenant
 
   # Get a specific %s
-  kubectl tenant get %s my-tenant specific-resource`,
-			resourceName, resourceName, resourceName, resourceName),
-		Args: cobra.RangeArgs(1, 2),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			tenantName := args[0]
+  kubectl tenant get %s my-tenant specific-resource
 
-			cfg, err := configFlags.ToRESTConfig()
-			if err != nil {
+  # List %s for several tenants
+  kubectl tenant get %s tenant-a,tenant-b
+
+  # List %s for all tenants
+  kubectl tenant get %s --all-tenants
+
+  # List %s with a label
+  kubectl tenant get %s my-tenant -l tier=gold
+
+  # List %s matching a glob pattern
+  kubectl tenant get %s my-tenant 'my-tenant-feature-*'
+
+  # List the %s a Tenant manifest would permit
+  kubectl tenant get %s my-tenant -f tenant.yaml --resolve cluster`,
+			resourceName, resourceName, resourceName, resourceName,
+			resourceName, resourceName, resourceName, resourceName,
+			resourceName, resourceName, resourceName, resourceName,
+			resourceName, resourceName),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if allTenants {
+				return nil
+			}
+			return cobra.MinimumNArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// With --all-tenants there is no tenant argument, only resource names
+			nameArgs := args
+			if !allTenants {
+				nameArgs = args[1:]
+			}
+			filter.setNames(nameArgs)
+			if err := filter.complete(opts); err != nil {
 				return err
 			}
 			ctx := cmd.Context()
+			dyn, err := offline.dynamicClient(ctx, configFlags, ioStreams)
+			if err != nil {
+				return err
+			}
+			if err := filter.namespaces.complete(ctx, dyn); err != nil {
+				return err
+			}
+
+			if allTenants || strings.Contains(args[0], ",") {
+				var tenantNames []string
+				switch {
+				case allTenants && offline.active():
+					tenantNames = offline.tenantNames()
+				case allTenants:
+					cfg, err := configFlags.ToRESTConfig()
+					if err != nil {
+						return err
+					}
+					tenantNames, err = listAllTenantNames(ctx, cfg, operator)
+					if err != nil {
+						return err
+					}
+				default:
+					tenantNames = splitTenantNames(args[0])
+				}
+				return listResourcesForTenants(ctx, dyn, tenantNames, resourceName, opts, filter, printFlags, ioStreams)
+			}
+
+			tenantName := args[0]
 
 			// If a specific resource name is provided, validate and get it
-			if len(args) > 1 {
-				resourceToGet := args[1]
-				return handleSpecificResource(ctx, cfg, tenantName, resourceName, resourceToGet, opts, printFlags, ioStreams)
+			if resourceToGet, ok := filter.singleName(); ok {
+				return handleSpecificResource(ctx, dyn, tenantName, resourceName, resourceToGet, opts, printFlags, ioStreams)
 			}
 
-			return listResources(ctx, cfg, tenantName, opts, printFlags, ioStreams)
+			// Explicit names must all be permitted, like a single name is
+			filter.requirePermitted = true
+
+			return listResources(ctx, dyn, tenantName, opts, filter, printFlags, ioStreams)
 		},
 	}
 
 	printFlags.AddFlags(cmd)
+	filter.addFlags(cmd)
+	if resourceName == "namespaces" {
+		filter.namespaces.addFlags(cmd)
+	}
+	cmd.Flags().BoolVarP(&allTenants, "all-tenants", "A", false, "List the resources of all tenants")
+	operator.addFlags(cmd)
+	offline.addFlags(cmd)
 	return cmd
 }
 
 func handleSpecificResource(
 	ctx context.Context,
-	cfg *rest.Config,
+	dyn dynamic.Interface,
 	tenantName string,
 	resourceType string,
 	resourceName string,
@@ -234,22 +375,11 @@ func handleSpecificResource(
 	printFlags *get.PrintFlags,
 	ioStreams genericiooptions.IOStreams,
 ) error {
-	dyn, err := dynamic.NewForConfig(cfg)
+	tenant, err := getTenant(ctx, dyn, tenantName, ioStreams.ErrOut)
 	if err != nil {
 		return err
 	}
 
-	tenantGVR := schema.GroupVersionResource{
-		Group:    "tenantoperator.stakater.com",
-		Version:  "v1beta3",
-		Resource: "tenants",
-	}
-
-	tenant, err := dyn.Resource(tenantGVR).Get(ctx, tenantName, metav1.GetOptions{})
-	if err != nil {
-		return fmt.Errorf("get tenant %q: %w", tenantName, err)
-	}
-
 	allowedResources := opts.extractTenantResources(tenant)
 
 	allowed := false
@@ -278,50 +408,121 @@ func handleSpecificResource(
 
 func listResources(
 	ctx context.Context,
-	cfg *rest.Config,
+	dyn dynamic.Interface,
 	tenantName string,
 	opts getOptions,
+	filter *listFilter,
 	printFlags *get.PrintFlags,
 	ioStreams genericiooptions.IOStreams,
 ) error {
-	// dynamic client to read the Tenant (unstructured)
-	dyn, err := dynamic.NewForConfig(cfg)
+	tenant, items, err := fetchTenantResources(ctx, dyn, tenantName, opts, filter, ioStreams.ErrOut)
 	if err != nil {
 		return err
 	}
 
-	tenantGVR := schema.GroupVersionResource{
-		Group:    "tenantoperator.stakater.com", // CRD group
-		Version:  "v1beta3",                     // adjust if your CRD version differs
-		Resource: "tenants",
+	results := []tenantResources{{tenant: tenantName, object: tenant, items: items}}
+	if opts.resource.Resource == "namespaces" && isTableOutput(printFlags) {
+		return printNamespaceTable(results, false, printFlags, ioStreams)
 	}
+	if opts.resource.Resource == "quotas" && isWideOutput(printFlags) {
+		return printQuotaTable(results, false, printFlags, ioStreams)
+	}
+	return printResourceList(opts, items, printFlags, ioStreams)
+}
 
-	var tenant *unstructured.Unstructured
-	tenant, err = dyn.Resource(tenantGVR).Get(ctx, tenantName, metav1.GetOptions{})
+// fetchTenantResources reads the Tenant and the objects listed in its status
+// that pass the filter, sorted by name. Name filters apply to the names in the status.
+// Selectors are evaluated by the API server when the resource may be listed,
+// and client-side on individually fetched objects otherwise.
+func fetchTenantResources(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantName string,
+	opts getOptions,
+	filter *listFilter,
+	warnOut io.Writer,
+) (*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+	tenant, err := getTenant(ctx, dyn, tenantName, warnOut)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, err := resolveTenantResources(ctx, dyn, tenant, opts, filter)
 	if err != nil {
-		return fmt.Errorf("get tenant %q: %w", tenantName, err)
+		return nil, nil, err
 	}
+	return tenant, items, nil
+}
 
-	names := opts.extractTenantResources(tenant)
-	if len(names) == 0 {
-		return printResourceList(opts, []*unstructured.Unstructured{}, printFlags, ioStreams)
+// resolveTenantResources fetches the objects of the resource type that tenant
+// is permitted and that match the filter.
+func resolveTenantResources(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenant *unstructured.Unstructured,
+	opts getOptions,
+	filter *listFilter,
+) ([]*unstructured.Unstructured, error) {
+	permitted := opts.extractTenantResources(tenant)
+	if filter.namespaces.active() {
+		permitted = filter.namespaces.filterNames(tenant)
+	}
+	names, err := filter.filterNames(opts.resource.Resource, tenant.GetName(), permitted)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []*unstructured.Unstructured
+	listed := false
+	if filter.hasSelectors() && len(names) > 0 {
+		items, err = filter.listPermitted(ctx, dyn, opts, names)
+		switch {
+		case err == nil:
+			listed = true
+		case !apierrors.IsForbidden(err):
+			return nil, err
+		}
 	}
 
-	items := make([]*unstructured.Unstructured, 0, len(names))
-	for _, name := range names {
-		obj, err := dyn.Resource(opts.resource).Get(ctx, name, metav1.GetOptions{})
-		if err != nil {
-			// If a name listed in the Tenant doesn't exist, skip it but keep going
-			continue
+	if !listed {
+		items = make([]*unstructured.Unstructured, 0, len(names))
+		for _, name := range names {
+			obj, err := dyn.Resource(opts.resource).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				// A name requested explicitly must exist, like a single name
+				if filter.requirePermitted && slices.Contains(filter.names, name) {
+					return nil, err
+				}
+				// If a name listed in the Tenant doesn't exist, skip it but keep going
+				continue
+			}
+			if filter.matches(obj, opts) {
+				items = append(items, obj)
+			}
 		}
-		items = append(items, obj)
 	}
 	// Sort by name to keep stable output (like kubectl)
 	sort.Slice(items, func(i, j int) bool {
 		return items[i].GetName() < items[j].GetName()
 	})
+	return items, nil
+}
 
-	return printResourceList(opts, items, printFlags, ioStreams)
+// getTenant reads the Tenant CR with the given name. When warnOut is set, stale
+// status and failing conditions of the Tenant are reported to it.
+func getTenant(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantName string,
+	warnOut io.Writer,
+) (*unstructured.Unstructured, error) {
+	tenant, err := dyn.Resource(tenantGVR).Get(ctx, tenantName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get tenant %q: %w", tenantName, err)
+	}
+	if warnOut != nil {
+		warnTenantStatus(warnOut, tenant)
+	}
+	return tenant, nil
 }
 
 func extractAvailableNames(u *unstructured.Unstructured, statusField string) []string {
@@ -368,47 +569,31 @@ func extractQuotaNames(u *unstructured.Unstructured) []string {
 }
 
 func extractNamespaceNames(u *unstructured.Unstructured) []string {
-	seen := map[string]struct{}{}
-	var out []string
-
-	deployedNs, found, err := unstructured.NestedStringSlice(u.Object, "status", "deployedNamespaces")
-	if err == nil && found {
-		for _, ns := range deployedNs {
-			ns = strings.TrimSpace(ns)
-			if ns != "" {
-				if _, dup := seen[ns]; !dup {
-					seen[ns] = struct{}{}
-					out = append(out, ns)
-				}
-			}
-		}
+	namespaces := extractTenantNamespaces(u)
+	out := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		out = append(out, ns.name)
 	}
+	return out
+}
 
-	sandboxes, found, err := unstructured.NestedMap(u.Object, "status", "deployedSandboxes")
-	if err == nil && found {
-		for _, val := range sandboxes {
-			ns, ok := val.(string)
-			if !ok {
-				continue
-			}
-			ns = strings.TrimSpace(ns)
-			if ns != "" {
-				if _, dup := seen[ns]; !dup {
-					seen[ns] = struct{}{}
-					out = append(out, ns)
-				}
-			}
-		}
-	}
+// operatorEndpoint locates the tenant-operator API service.
+type operatorEndpoint struct {
+	namespace string
+	service   string
+	port      string
+}
 
-	sort.Strings(out)
-	return out
+func (o *operatorEndpoint) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.namespace, "operator-namespace", "multi-tenant-operator",
+		"Namespace where tenant-operator is deployed")
+	cmd.Flags().StringVar(&o.service, "operator-service", "tenant-operator-api",
+		"Name of the tenant-operator API service")
+	cmd.Flags().StringVar(&o.port, "operator-port", "8080", "Port of the tenant-operator API service")
 }
 
 func newListCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
-	var operatorNamespace string
-	var operatorService string
-	var operatorPort string
+	operator := &operatorEndpoint{}
 	printFlags := get.NewGetPrintFlags()
 
 	cmd := &cobra.Command{
@@ -432,16 +617,12 @@ where the current user appears as an owner, editor, or viewer.`,
 			if err != nil {
 				return err
 			}
-			return listUserTenants(cmd.Context(), cfg, operatorNamespace, operatorService, operatorPort, printFlags, ioStreams)
+			return listUserTenants(cmd.Context(), cfg, operator, printFlags, ioStreams)
 		},
 	}
 
 	printFlags.AddFlags(cmd)
-	cmd.Flags().StringVar(&operatorNamespace, "operator-namespace", "multi-tenant-operator",
-		"Namespace where tenant-operator is deployed")
-	cmd.Flags().StringVar(&operatorService, "operator-service", "tenant-operator-api",
-		"Name of the tenant-operator API service")
-	cmd.Flags().StringVar(&operatorPort, "operator-port", "8080", "Port of the tenant-operator API service")
+	operator.addFlags(cmd)
 
 	return cmd
 }
@@ -506,78 +687,88 @@ func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 func listUserTenants(
 	ctx context.Context,
 	cfg *rest.Config,
-	namespace, service, port string,
+	operator *operatorEndpoint,
 	printFlags *get.PrintFlags,
 	ioStreams genericiooptions.IOStreams,
 ) error {
+	result, err := fetchUserTenants(ctx, cfg, operator)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Tenants) == 0 {
+		if _, err := fmt.Fprintln(ioStreams.Out, "No tenants found for the current user."); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	// If an output format is specified (-o json, -o yaml, etc.), use kubectl printers
+	if printFlags.OutputFormat != nil && *printFlags.OutputFormat != "" {
+		return printTenantList(result, printFlags, ioStreams)
+	}
+
+	// Default: human-readable table
+	w := tabwriter.NewWriter(ioStreams.Out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAME\tROLE"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, t := range result.Tenants {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", t.Name, t.Role); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// fetchUserTenants asks the tenant-operator API for the tenants of the calling user.
+func fetchUserTenants(ctx context.Context, cfg *rest.Config, operator *operatorEndpoint) (tenantListResponse, error) {
+	var result tenantListResponse
+
 	token, err := extractBearerToken(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to extract bearer token: %w", err)
+		return result, fmt.Errorf("failed to extract bearer token: %w", err)
 	}
 
 	proxyPath := fmt.Sprintf(
 		"/api/v1/namespaces/%s/services/%s:%s/proxy/api/v1/tenants",
-		namespace, service, port,
+		operator.namespace, operator.service, operator.port,
 	)
 
 	transport, err := rest.TransportFor(cfg)
 	if err != nil {
-		return fmt.Errorf("failed to create transport: %w", err)
+		return result, fmt.Errorf("failed to create transport: %w", err)
 	}
 
 	bodyBytes, err := json.Marshal(map[string]string{"token": token})
 	if err != nil {
-		return fmt.Errorf("failed to marshal request body: %w", err)
+		return result, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
 	url := strings.TrimRight(cfg.Host, "/") + proxyPath
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return err
+		return result, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	client := &http.Client{Transport: transport}
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to call tenant-operator API: %w", err)
+		return result, fmt.Errorf("failed to call tenant-operator API: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("tenant-operator API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return result, fmt.Errorf("tenant-operator API returned status %d: %s",
+			resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
-	var result tenantListResponse
-
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+		return result, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	if len(result.Tenants) == 0 {
-		if _, err := fmt.Fprintln(ioStreams.Out, "No tenants found for the current user."); err != nil {
-			return fmt.Errorf("failed to write output: %w", err)
-		}
-		return nil
-	}
-
-	// If an output format is specified (-o json, -o yaml, etc.), use kubectl printers
-	if printFlags.OutputFormat != nil && *printFlags.OutputFormat != "" {
-		return printTenantList(result, printFlags, ioStreams)
-	}
-
-	// Default: human-readable table
-	w := tabwriter.NewWriter(ioStreams.Out, 0, 4, 2, ' ', 0)
-	if _, err := fmt.Fprintln(w, "NAME\tROLE"); err != nil {
-		return fmt.Errorf("failed to write output: %w", err)
-	}
-	for _, t := range result.Tenants {
-		if _, err := fmt.Fprintf(w, "%s\t%s\n", t.Name, t.Role); err != nil {
-			return fmt.Errorf("failed to write output: %w", err)
-		}
-	}
-	return w.Flush()
+	return result, nil
 }
 
 func printTenantList(
@@ -614,6 +805,16 @@ func printTenantList(
 	return p.PrintObj(list, ioStreams.Out)
 }
 
+// isTableOutput reports whether the output format is a table rather than a
+// serialized list.
+func isTableOutput(printFlags *get.PrintFlags) bool {
+	return printFlags.OutputFormat == nil || *printFlags.OutputFormat == "" || isWideOutput(printFlags)
+}
+
+func isWideOutput(printFlags *get.PrintFlags) bool {
+	return printFlags.OutputFormat != nil && *printFlags.OutputFormat == "wide"
+}
+
 func printResourceList(
 	opts getOptions,
 	items []*unstructured.Unstructured,
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// manifestExtensions are the file extensions read from directories.
+var manifestExtensions = []string{".yaml", ".yml", ".json"}
+
+// manifestOptions are the -f/--filename and -R/--recursive flags of commands
+// that read manifests.
+type manifestOptions struct {
+	filenames []string
+	recursive bool
+}
+
+func (m *manifestOptions) addFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().StringSliceVarP(&m.filenames, "filename", "f", nil, usage)
+	cmd.Flags().BoolVarP(&m.recursive, "recursive", "R", false,
+		"Process the directory used in -f, --filename recursively")
+}
+
+// read decodes the objects of all files, in the order given; "-" reads stdin.
+// Directories contribute their .yaml, .yml and .json files in lexical order, and
+// List objects are expanded into their items.
+func (m *manifestOptions) read(stdin io.Reader) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	for _, filename := range m.filenames {
+		if filename == "-" {
+			read, err := decodeManifests(stdin, "stdin")
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, read...)
+			continue
+		}
+
+		paths, err := m.expand(filename)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+			read, err := decodeManifests(f, path)
+			_ = f.Close()
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, read...)
+		}
+	}
+	return objs, nil
+}
+
+// expand lists the manifest files of filename, which may be a directory.
+func (m *manifestOptions) expand(filename string) ([]string, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{filename}, nil
+	}
+
+	var paths []string
+	err = filepath.WalkDir(filename, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != filename && !m.recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if slices.Contains(manifestExtensions, strings.ToLower(filepath.Ext(path))) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+// decodeManifests decodes the YAML or JSON documents of r; source names r in errors.
+func decodeManifests(r io.Reader, source string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+
+	var objs []*unstructured.Unstructured
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("decode %s: %w", source, err)
+		}
+		// Empty documents between "---" separators decode as null
+		if doc := bytes.TrimSpace(raw); len(doc) == 0 || string(doc) == "null" {
+			continue
+		}
+
+		obj, _, err := unstructured.UnstructuredJSONScheme.Decode(raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", source, err)
+		}
+		switch obj := obj.(type) {
+		case *unstructured.UnstructuredList:
+			for i := range obj.Items {
+				objs = append(objs, &obj.Items[i])
+			}
+		case *unstructured.Unstructured:
+			objs = append(objs, obj)
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+)
+
+// defaultMatrixResources are reported when no resource types are given.
+var defaultMatrixResources = []string{"storageclasses", "ingressclasses", "priorityclasses", "quotas"}
+
+// matrixRow is one cluster object with the tenants permitted to use it.
+type matrixRow struct {
+	resource  string
+	name      string
+	permitted []bool
+	count     int
+}
+
+func newMatrixCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var output string
+	var showUnpermitted bool
+	offline := &offlineOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "matrix [resource...]",
+		Short: "Report which tenants may use which cluster resources",
+		Long: `Report a grid of tenants against the cluster resources they are permitted to use.
+
+Each row is a resource, each tenant column shows whether the tenant may use it, and
+the TENANTS column counts the tenants permitted to use it. By default storage classes,
+ingress classes, priority classes and quotas are reported.
+
+With --show-unpermitted, cluster objects that no tenant may use are listed as well
+and highlighted.
+
+With -f the Tenants are read from manifests instead of the cluster, and what they
+may use is derived from their spec; --resolve looks the cluster objects up in the
+cluster or in a directory of exported objects.`,
+		Example: `  # Report all tenants against the default resources
+  kubectl tenant matrix
+
+  # Report storage classes only, including those no tenant may use
+  kubectl tenant matrix storageclasses --show-unpermitted
+
+  # Export the report as CSV
+  kubectl tenant matrix -o csv > access-review.csv
+
+  # Report the Tenant manifests of a pull request
+  kubectl tenant matrix -f tenants/ --resolve cluster --show-unpermitted`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceTypes, err := matrixResourceTypes(args)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains([]string{"table", "csv", "markdown"}, output) {
+				return fmt.Errorf("unsupported output format %q: must be one of table, csv, markdown", output)
+			}
+
+			ctx := cmd.Context()
+			dyn, err := offline.dynamicClient(ctx, configFlags, ioStreams)
+			if err != nil {
+				return err
+			}
+
+			list, err := dyn.Resource(tenantGVR).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return fmt.Errorf("list tenants: %w", err)
+			}
+			tenants := list.Items
+			sort.Slice(tenants, func(i, j int) bool {
+				return tenants[i].GetName() < tenants[j].GetName()
+			})
+			for i := range tenants {
+				warnTenantStatus(ioStreams.ErrOut, &tenants[i])
+			}
+
+			var clusterNames map[string][]string
+			if showUnpermitted {
+				clusterNames, err = listClusterNames(ctx, dyn, resourceTypes)
+				if err != nil {
+					return err
+				}
+			}
+
+			rows := buildMatrix(tenants, resourceTypes, clusterNames)
+			return writeMatrix(ioStreams.Out, output, tenants, rows)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table, csv or markdown")
+	cmd.Flags().BoolVar(&showUnpermitted, "show-unpermitted", false,
+		"Also list cluster objects that no tenant is permitted to use")
+	offline.addFlags(cmd)
+
+	return cmd
+}
+
+// matrixResourceTypes resolves the requested resource types, defaulting to the class and quota types.
+func matrixResourceTypes(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return defaultMatrixResources, nil
+	}
+	var out []string
+	for _, arg := range args {
+		resourceType, _, ok := lookupClusterResource(arg)
+		if !ok {
+			return nil, fmt.Errorf("unsupported resource type %q", arg)
+		}
+		if !slices.Contains(out, resourceType) {
+			out = append(out, resourceType)
+		}
+	}
+	return out, nil
+}
+
+// listClusterNames lists the names of all cluster objects of the given resource types.
+func listClusterNames(ctx context.Context, dyn dynamic.Interface, resourceTypes []string) (map[string][]string, error) {
+	out := map[string][]string{}
+	for _, resourceType := range resourceTypes {
+		opts := ClusterResources[resourceType]
+		list, err := dyn.Resource(opts.resource).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", resourceType, err)
+		}
+		for _, item := range list.Items {
+			out[resourceType] = append(out[resourceType], item.GetName())
+		}
+	}
+	return out, nil
+}
+
+// buildMatrix runs the extractor of each resource type over every tenant. Names in
+// clusterNames that no tenant permits are added as rows with no permitted tenant.
+func buildMatrix(
+	tenants []unstructured.Unstructured,
+	resourceTypes []string,
+	clusterNames map[string][]string,
+) []matrixRow {
+	var rows []matrixRow
+	for _, resourceType := range resourceTypes {
+		opts := ClusterResources[resourceType]
+
+		byName := map[string]*matrixRow{}
+		addRow := func(name string) *matrixRow {
+			if row, ok := byName[name]; ok {
+				return row
+			}
+			row := &matrixRow{resource: resourceType, name: name, permitted: make([]bool, len(tenants))}
+			byName[name] = row
+			return row
+		}
+
+		for i := range tenants {
+			for _, name := range opts.extractTenantResources(&tenants[i]) {
+				row := addRow(name)
+				row.permitted[i] = true
+				row.count++
+			}
+		}
+		for _, name := range clusterNames[resourceType] {
+			addRow(name)
+		}
+
+		names := make([]string, 0, len(byName))
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rows = append(rows, *byName[name])
+		}
+	}
+	return rows
+}
+
+func writeMatrix(out io.Writer, format string, tenants []unstructured.Unstructured, rows []matrixRow) error {
+	header := []string{"RESOURCE", "NAME"}
+	for i := range tenants {
+		header = append(header, tenants[i].GetName())
+	}
+	header = append(header, "TENANTS")
+
+	switch format {
+	case "csv":
+		w := csv.NewWriter(out)
+		if err := w.Write(header); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		for _, row := range rows {
+			record := []string{row.resource, row.name}
+			for _, permitted := range row.permitted {
+				record = append(record, strconv.FormatBool(permitted))
+			}
+			record = append(record, strconv.Itoa(row.count))
+			if err := w.Write(record); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		}
+		w.Flush()
+		return w.Error()
+
+	case "markdown":
+		var b strings.Builder
+		b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+		for _, row := range rows {
+			name := row.name
+			if row.count == 0 {
+				name = "**" + name + "** (unpermitted)"
+			}
+			cells := []string{row.resource, name}
+			for _, permitted := range row.permitted {
+				cells = append(cells, matrixMark(permitted, ""))
+			}
+			cells = append(cells, strconv.Itoa(row.count))
+			b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+		if _, err := io.WriteString(out, b.String()); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+
+	default:
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		for _, row := range rows {
+			cells := []string{row.resource, row.name}
+			for _, permitted := range row.permitted {
+				cells = append(cells, matrixMark(permitted, "-"))
+			}
+			count := strconv.Itoa(row.count)
+			if row.count == 0 {
+				count += " (unpermitted)"
+			}
+			cells = append(cells, count)
+			if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+		}
+		return w.Flush()
+	}
+}
+
+func matrixMark(permitted bool, empty string) string {
+	if permitted {
+		return "✓"
+	}
+	return empty
+}
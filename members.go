@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/cmd/get"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// openshiftGroupGVR is the OpenShift user group resource used to expand group members.
+var openshiftGroupGVR = schema.GroupVersionResource{
+	Group:    "user.openshift.io",
+	Version:  "v1",
+	Resource: "groups",
+}
+
+// accessControlRoles maps the keys of spec.accessControl to the role names shown to users.
+var accessControlRoles = []struct {
+	field string
+	role  string
+}{
+	{field: "owners", role: "owner"},
+	{field: "editors", role: "editor"},
+	{field: "viewers", role: "viewer"},
+}
+
+type tenantMember struct {
+	Name string
+	Kind string
+	Role string
+	// Via is the group a user was resolved from when groups are expanded.
+	Via string
+}
+
+func newGetMembersCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var expandGroups bool
+	printFlags := get.NewGetPrintFlags()
+
+	cmd := &cobra.Command{
+		Use:   "members <tenant>",
+		Short: "List users and groups with access to a Tenant",
+		Long: `List the users and groups granted access to a Tenant, together with their role.
+
+Members are read from spec.accessControl of the Tenant CR. With --expand-groups,
+OpenShift groups (user.openshift.io) are resolved to the users they contain.`,
+		Example: `  # List members of my-tenant
+  kubectl tenant get members my-tenant
+
+  # Resolve OpenShift groups to their users
+  kubectl tenant get members my-tenant --expand-groups
+
+  # List members as JSON
+  kubectl tenant get members my-tenant -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			tenant, err := getTenant(ctx, dyn, args[0], ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			members := extractMembers(tenant)
+
+			if expandGroups {
+				members, err = expandGroupMembers(ctx, configFlags, dyn, members, ioStreams)
+				if err != nil {
+					return err
+				}
+			}
+
+			return printMembers(members, expandGroups, printFlags, ioStreams)
+		},
+	}
+
+	printFlags.AddFlags(cmd)
+	cmd.Flags().BoolVar(&expandGroups, "expand-groups", false,
+		"Resolve OpenShift groups to their member users")
+
+	return cmd
+}
+
+// extractMembers returns the users and groups listed in spec.accessControl,
+// ordered by role (owners, editors, viewers), then users before groups.
+func extractMembers(u *unstructured.Unstructured) []tenantMember {
+	var out []tenantMember
+	for _, r := range accessControlRoles {
+		for _, subject := range []struct{ field, kind string }{{"users", "User"}, {"groups", "Group"}} {
+			names, found, err := unstructured.NestedStringSlice(u.Object, "spec", "accessControl", r.field, subject.field)
+			if err != nil || !found {
+				continue
+			}
+			for _, name := range names {
+				out = append(out, tenantMember{Name: name, Kind: subject.kind, Role: r.role})
+			}
+		}
+	}
+	return out
+}
+
+// expandGroupMembers appends the users of each OpenShift group found in members.
+// On clusters that don't serve user.openshift.io groups are left unexpanded.
+func expandGroupMembers(
+	ctx context.Context,
+	configFlags *genericclioptions.ConfigFlags,
+	dyn dynamic.Interface,
+	members []tenantMember,
+	ioStreams genericiooptions.IOStreams,
+) ([]tenantMember, error) {
+	disco, err := configFlags.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := disco.ServerResourcesForGroupVersion(openshiftGroupGVR.GroupVersion().String()); err != nil {
+		if apierrors.IsNotFound(err) {
+			_, _ = fmt.Fprintln(ioStreams.ErrOut,
+				"Warning: OpenShift groups (user.openshift.io) are not available on this cluster; groups are not expanded")
+			return members, nil
+		}
+		return nil, err
+	}
+
+	out := make([]tenantMember, 0, len(members))
+	for _, m := range members {
+		out = append(out, m)
+		if m.Kind != "Group" {
+			continue
+		}
+
+		group, err := dyn.Resource(openshiftGroupGVR).Get(ctx, m.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				_, _ = fmt.Fprintf(ioStreams.ErrOut, "Warning: group %q not found\n", m.Name)
+				continue
+			}
+			return nil, fmt.Errorf("get group %q: %w", m.Name, err)
+		}
+
+		users, _, _ := unstructured.NestedStringSlice(group.Object, "users")
+		for _, user := range users {
+			out = append(out, tenantMember{Name: user, Kind: "User", Role: m.Role, Via: m.Name})
+		}
+	}
+	return out, nil
+}
+
+func printMembers(
+	members []tenantMember,
+	showVia bool,
+	printFlags *get.PrintFlags,
+	ioStreams genericiooptions.IOStreams,
+) error {
+	// If an output format is specified (-o json, -o yaml, etc.), use kubectl printers
+	if printFlags.OutputFormat != nil && *printFlags.OutputFormat != "" && *printFlags.OutputFormat != "wide" {
+		items := make([]unstructured.Unstructured, 0, len(members))
+		for _, m := range members {
+			obj := map[string]any{
+				"apiVersion": "tenantoperator.stakater.com/v1beta3",
+				"kind":       "TenantMember",
+				"metadata": map[string]any{
+					"name": m.Name,
+				},
+				"subjectKind": m.Kind,
+				"role":        m.Role,
+			}
+			if m.Via != "" {
+				obj["via"] = m.Via
+			}
+			items = append(items, unstructured.Unstructured{Object: obj})
+		}
+
+		list := &unstructured.UnstructuredList{
+			Object: map[string]any{
+				"apiVersion": "v1",
+				"kind":       "List",
+			},
+			Items: items,
+		}
+
+		p, err := printFlags.ToPrinter()
+		if err != nil {
+			return err
+		}
+		return p.PrintObj(list, ioStreams.Out)
+	}
+
+	if len(members) == 0 {
+		if _, err := fmt.Fprintln(ioStreams.Out, "No members found."); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(ioStreams.Out, 0, 4, 2, ' ', 0)
+	header := "NAME\tKIND\tROLE"
+	if showVia {
+		header += "\tVIA"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, m := range members {
+		line := fmt.Sprintf("%s\t%s\t%s", m.Name, m.Kind, m.Role)
+		if showVia {
+			via := m.Via
+			if via == "" {
+				via = "<direct>"
+			}
+			line += "\t" + via
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+type memberChangeOptions struct {
+	users  []string
+	groups []string
+	role   string
+}
+
+func (o *memberChangeOptions) addFlags(cmd *cobra.Command, roleUsage string) {
+	cmd.Flags().StringSliceVar(&o.users, "user", nil, "User to change (may be repeated or comma-separated)")
+	cmd.Flags().StringSliceVar(&o.groups, "group", nil, "Group to change (may be repeated or comma-separated)")
+	cmd.Flags().StringVar(&o.role, "role", "", roleUsage)
+}
+
+// roleField validates the --role flag and returns the matching spec.accessControl key.
+func (o *memberChangeOptions) roleField() (string, error) {
+	for _, r := range accessControlRoles {
+		if o.role == r.role {
+			return r.field, nil
+		}
+	}
+	return "", fmt.Errorf("invalid role %q: must be one of owner, editor, viewer", o.role)
+}
+
+func (o *memberChangeOptions) validate() error {
+	if len(o.users) == 0 && len(o.groups) == 0 {
+		return fmt.Errorf("at least one --user or --group is required")
+	}
+	return nil
+}
+
+func newAddMemberCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	o := &memberChangeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "add-member <tenant>",
+		Short: "Grant a user or group a role on a Tenant",
+		Long: `Grant users or groups a role on a Tenant by adding them to spec.accessControl.
+
+The change is written with a JSON patch that is retried when the Tenant was
+modified concurrently. The access control block is printed as a diff.`,
+		Example: `  # Make alice an editor of my-tenant
+  kubectl tenant add-member my-tenant --user alice --role editor
+
+  # Grant a group viewer access, validating the change on the server only
+  kubectl tenant add-member my-tenant --group auditors --role viewer --dry-run=server`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.validate(); err != nil {
+				return err
+			}
+			field, err := o.roleField()
+			if err != nil {
+				return err
+			}
+
+			return changeMembers(cmd, configFlags, args[0], ioStreams, func(ac map[string]interface{}) error {
+				role, _ := ac[field].(map[string]interface{})
+				if role == nil {
+					role = map[string]interface{}{}
+				}
+				role["users"] = addNames(role["users"], o.users)
+				role["groups"] = addNames(role["groups"], o.groups)
+				ac[field] = pruneEmpty(role)
+				return nil
+			})
+		},
+	}
+
+	o.addFlags(cmd, "Role to grant: owner, editor or viewer")
+	_ = cmd.MarkFlagRequired("role")
+	cmdutil.AddDryRunFlag(cmd)
+
+	return cmd
+}
+
+func newRemoveMemberCmd(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	o := &memberChangeOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "remove-member <tenant>",
+		Short: "Revoke a user's or group's role on a Tenant",
+		Long: `Revoke users or groups from a Tenant by removing them from spec.accessControl.
+
+Without --role the members are removed from every role. The change is written
+with a JSON patch that is retried when the Tenant was modified concurrently.
+The access control block is printed as a diff.`,
+		Example: `  # Remove alice from every role of my-tenant
+  kubectl tenant remove-member my-tenant --user alice
+
+  # Revoke viewer access of a group
+  kubectl tenant remove-member my-tenant --group auditors --role viewer`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := o.validate(); err != nil {
+				return err
+			}
+			fields := make([]string, 0, len(accessControlRoles))
+			if o.role != "" {
+				field, err := o.roleField()
+				if err != nil {
+					return err
+				}
+				fields = append(fields, field)
+			} else {
+				for _, r := range accessControlRoles {
+					fields = append(fields, r.field)
+				}
+			}
+
+			return changeMembers(cmd, configFlags, args[0], ioStreams, func(ac map[string]interface{}) error {
+				for _, field := range fields {
+					role, _ := ac[field].(map[string]interface{})
+					if role == nil {
+						continue
+					}
+					role["users"] = removeNames(role["users"], o.users)
+					role["groups"] = removeNames(role["groups"], o.groups)
+					if role = pruneEmpty(role); len(role) == 0 {
+						delete(ac, field)
+					} else {
+						ac[field] = role
+					}
+				}
+				return nil
+			})
+		},
+	}
+
+	o.addFlags(cmd, "Role to revoke: owner, editor or viewer (default: all roles)")
+	cmdutil.AddDryRunFlag(cmd)
+
+	return cmd
+}
+
+// changeMembers applies mutate to spec.accessControl of the tenant and prints the resulting diff.
+func changeMembers(
+	cmd *cobra.Command,
+	configFlags *genericclioptions.ConfigFlags,
+	tenantName string,
+	ioStreams genericiooptions.IOStreams,
+	mutate func(map[string]interface{}) error,
+) error {
+	dryRun, err := cmdutil.GetDryRunStrategy(cmd)
+	if err != nil {
+		return err
+	}
+	cfg, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	before, after, err := updateTenantSpec(cmd.Context(), dyn, tenantName, []string{"accessControl"}, dryRun, mutate)
+	if err != nil {
+		return err
+	}
+
+	if err := printFieldDiff(ioStreams.Out, "accessControl", before, after); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return printPatchResult(ioStreams.Out, tenantName, before, after, dryRun)
+}
+
+// addNames appends names missing from list, which holds a JSON string array.
+func addNames(list interface{}, names []string) []interface{} {
+	out, _ := list.([]interface{})
+	for _, name := range names {
+		if !slices.Contains(out, interface{}(name)) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// removeNames drops names from list, which holds a JSON string array.
+func removeNames(list interface{}, names []string) []interface{} {
+	existing, _ := list.([]interface{})
+	out := make([]interface{}, 0, len(existing))
+	for _, entry := range existing {
+		if s, ok := entry.(string); ok && slices.Contains(names, s) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// pruneEmpty drops empty lists so the Tenant doesn't accumulate "users: []" entries.
+func pruneEmpty(m map[string]interface{}) map[string]interface{} {
+	for k, v := range m {
+		if l, ok := v.([]interface{}); ok && len(l) == 0 {
+			delete(m, k)
+		}
+	}
+	return m
+}
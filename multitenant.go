@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubectl/pkg/cmd/get"
+)
+
+// tenantKey is the label the operator puts on the namespaces of a tenant. As an
+// annotation it records which tenant an object was listed for when the results
+// of several tenants are merged into one list.
+const tenantKey = "stakater.com/tenant"
+
+// tenantResources holds the objects permitted for one tenant.
+type tenantResources struct {
+	tenant string
+	object *unstructured.Unstructured
+	items  []*unstructured.Unstructured
+	err    error
+}
+
+// splitTenantNames splits a comma-separated tenant argument, dropping empty entries.
+func splitTenantNames(arg string) []string {
+	var out []string
+	for _, name := range strings.Split(arg, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// listAllTenantNames lists the Tenant CRs in the cluster. Users who may not list
+// Tenants fall back to the tenants the operator API reports for them.
+func listAllTenantNames(ctx context.Context, cfg *rest.Config, operator *operatorEndpoint) ([]string, error) {
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	list, err := dyn.Resource(tenantGVR).List(ctx, metav1.ListOptions{})
+	switch {
+	case err == nil:
+		for _, t := range list.Items {
+			names = append(names, t.GetName())
+		}
+	case apierrors.IsForbidden(err):
+		result, err := fetchUserTenants(ctx, cfg, operator)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range result.Tenants {
+			names = append(names, t.Name)
+		}
+	default:
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// listResourcesForTenants looks up the permitted resources of every tenant concurrently
+// and prints them as one list. A tenant that fails is reported without hiding the others.
+func listResourcesForTenants(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantNames []string,
+	resourceType string,
+	opts getOptions,
+	filter *listFilter,
+	printFlags *get.PrintFlags,
+	ioStreams genericiooptions.IOStreams,
+) error {
+	results := make([]tenantResources, len(tenantNames))
+	var wg sync.WaitGroup
+	for i, tenantName := range tenantNames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tenant, items, err := fetchTenantResources(ctx, dyn, tenantName, opts, filter, ioStreams.ErrOut)
+			results[i] = tenantResources{tenant: tenantName, object: tenant, items: items, err: err}
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for i := range results {
+		if results[i].err != nil {
+			failed++
+			_, _ = fmt.Fprintf(ioStreams.ErrOut, "error: %v\n", results[i].err)
+		}
+	}
+
+	if err := printTenantResources(results, opts, printFlags, ioStreams); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to list %s for %d of %d tenants", resourceType, failed, len(tenantNames))
+	}
+	return nil
+}
+
+// printTenantResources prints the merged results with a TENANT column, or as a
+// list of annotated objects for the non-tabular output formats.
+func printTenantResources(
+	results []tenantResources,
+	opts getOptions,
+	printFlags *get.PrintFlags,
+	ioStreams genericiooptions.IOStreams,
+) error {
+	if !isTableOutput(printFlags) {
+		var items []*unstructured.Unstructured
+		for _, r := range results {
+			for _, item := range r.items {
+				item = item.DeepCopy()
+				annotations := item.GetAnnotations()
+				if annotations == nil {
+					annotations = map[string]string{}
+				}
+				annotations[tenantKey] = r.tenant
+				item.SetAnnotations(annotations)
+				items = append(items, item)
+			}
+		}
+		return printResourceList(opts, items, printFlags, ioStreams)
+	}
+	if opts.resource.Resource == "namespaces" {
+		return printNamespaceTable(results, true, printFlags, ioStreams)
+	}
+	if opts.resource.Resource == "quotas" && isWideOutput(printFlags) {
+		return printQuotaTable(results, true, printFlags, ioStreams)
+	}
+
+	table := &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Tenant", Type: "string"},
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Age", Type: "string"},
+		},
+	}
+	for _, r := range results {
+		for _, item := range r.items {
+			table.Rows = append(table.Rows, metav1.TableRow{
+				Cells: []interface{}{r.tenant, item.GetName(), translateTimestampSince(item.GetCreationTimestamp())},
+			})
+		}
+	}
+
+	p, err := printFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return p.PrintObj(table, ioStreams.Out)
+}
+
+// translateTimestampSince returns the elapsed time since timestamp in
+// human-readable approximation, like the AGE column of kubectl get.
+func translateTimestampSince(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(timestamp.Time))
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/cmd/get"
+)
+
+// Namespace types shown in the TYPE column of namespace listings.
+const (
+	namespaceTypeTenant  = "tenant"
+	namespaceTypeSandbox = "sandbox"
+)
+
+var selfSubjectReviewGVR = schema.GroupVersionResource{
+	Group:    "authentication.k8s.io",
+	Version:  "v1",
+	Resource: "selfsubjectreviews",
+}
+
+// tenantNamespace is a namespace deployed for a tenant. Sandboxes carry the
+// user they were created for.
+type tenantNamespace struct {
+	name  string
+	kind  string
+	owner string
+}
+
+// extractTenantNamespaces reads status.deployedNamespaces and the
+// status.deployedSandboxes map (keyed by user), sorted by name.
+func extractTenantNamespaces(u *unstructured.Unstructured) []tenantNamespace {
+	byName := map[string]tenantNamespace{}
+
+	deployedNs, _, _ := unstructured.NestedStringSlice(u.Object, "status", "deployedNamespaces")
+	for _, ns := range deployedNs {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			byName[ns] = tenantNamespace{name: ns, kind: namespaceTypeTenant}
+		}
+	}
+
+	// A namespace listed as both is reported as the sandbox, which also names its owner
+	sandboxes, _, _ := unstructured.NestedMap(u.Object, "status", "deployedSandboxes")
+	for user, val := range sandboxes {
+		ns, ok := val.(string)
+		if !ok {
+			continue
+		}
+		if ns = strings.TrimSpace(ns); ns != "" {
+			byName[ns] = tenantNamespace{name: ns, kind: namespaceTypeSandbox, owner: user}
+		}
+	}
+
+	out := make([]tenantNamespace, 0, len(byName))
+	for _, ns := range byName {
+		out = append(out, ns)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+// namespaceFilter narrows namespace listings by namespace type and sandbox owner.
+type namespaceFilter struct {
+	sandboxesOnly    bool
+	excludeSandboxes bool
+	mine             bool
+
+	// user is the caller, resolved for --mine
+	user string
+}
+
+func (f *namespaceFilter) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.sandboxesOnly, "sandboxes-only", false, "List only sandbox namespaces")
+	cmd.Flags().BoolVar(&f.excludeSandboxes, "exclude-sandboxes", false,
+		"List only the tenant namespaces, without sandboxes")
+	cmd.Flags().BoolVar(&f.mine, "mine", false, "List only the sandboxes of the current user")
+	cmd.MarkFlagsMutuallyExclusive("sandboxes-only", "exclude-sandboxes")
+	cmd.MarkFlagsMutuallyExclusive("mine", "exclude-sandboxes")
+}
+
+func (f *namespaceFilter) active() bool {
+	return f.sandboxesOnly || f.excludeSandboxes || f.mine
+}
+
+// complete resolves the current user for --mine.
+func (f *namespaceFilter) complete(ctx context.Context, dyn dynamic.Interface) error {
+	if !f.mine {
+		return nil
+	}
+	var err error
+	f.user, err = currentUser(ctx, dyn)
+	return err
+}
+
+// filterNames returns the names of the tenant namespaces that pass the filter.
+func (f *namespaceFilter) filterNames(tenant *unstructured.Unstructured) []string {
+	var out []string
+	for _, ns := range extractTenantNamespaces(tenant) {
+		if f.keep(ns) {
+			out = append(out, ns.name)
+		}
+	}
+	return out
+}
+
+func (f *namespaceFilter) keep(ns tenantNamespace) bool {
+	switch {
+	case f.excludeSandboxes:
+		return ns.kind == namespaceTypeTenant
+	case f.mine:
+		return ns.kind == namespaceTypeSandbox && ns.owner == f.user
+	case f.sandboxesOnly:
+		return ns.kind == namespaceTypeSandbox
+	}
+	return true
+}
+
+// currentUser asks the API server who the caller is authenticated as.
+func currentUser(ctx context.Context, dyn dynamic.Interface) (string, error) {
+	review := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": selfSubjectReviewGVR.GroupVersion().String(),
+		"kind":       "SelfSubjectReview",
+	}}
+	result, err := dyn.Resource(selfSubjectReviewGVR).Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return "", fmt.Errorf("look up current user: %w", err)
+	}
+	username, _, _ := unstructured.NestedString(result.Object, "status", "userInfo", "username")
+	if username == "" {
+		return "", fmt.Errorf("look up current user: no username in SelfSubjectReview")
+	}
+	return username, nil
+}
+
+// printNamespaceTable prints namespaces with their TYPE and OWNER, and the
+// TENANT they belong to when withTenant is set.
+func printNamespaceTable(
+	results []tenantResources,
+	withTenant bool,
+	printFlags *get.PrintFlags,
+	ioStreams genericiooptions.IOStreams,
+) error {
+	table := &metav1.Table{}
+	if withTenant {
+		table.ColumnDefinitions = append(table.ColumnDefinitions,
+			metav1.TableColumnDefinition{Name: "Tenant", Type: "string"})
+	}
+	table.ColumnDefinitions = append(table.ColumnDefinitions,
+		metav1.TableColumnDefinition{Name: "Name", Type: "string", Format: "name"},
+		metav1.TableColumnDefinition{Name: "Type", Type: "string"},
+		metav1.TableColumnDefinition{Name: "Owner", Type: "string"},
+		metav1.TableColumnDefinition{Name: "Status", Type: "string"},
+		metav1.TableColumnDefinition{Name: "Age", Type: "string"},
+	)
+
+	for _, r := range results {
+		namespaces := map[string]tenantNamespace{}
+		if r.object != nil {
+			for _, ns := range extractTenantNamespaces(r.object) {
+				namespaces[ns.name] = ns
+			}
+		}
+		for _, item := range r.items {
+			ns := namespaces[item.GetName()]
+			owner := ns.owner
+			if owner == "" {
+				owner = "<none>"
+			}
+			phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+
+			var cells []interface{}
+			if withTenant {
+				cells = append(cells, r.tenant)
+			}
+			cells = append(cells, item.GetName(), ns.kind, owner, phase, translateTimestampSince(item.GetCreationTimestamp()))
+			table.Rows = append(table.Rows, metav1.TableRow{Cells: cells})
+		}
+	}
+
+	p, err := printFlags.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return p.PrintObj(table, ioStreams.Out)
+}
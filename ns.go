@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+)
+
+func newNsCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ns <tenant> [namespace]",
+		Short: "Switch the kubeconfig namespace to a namespace of a Tenant",
+		Long: `Switch the namespace of the current kubeconfig context to a namespace of a Tenant.
+
+The namespace must be one of the namespaces or sandboxes published in the Tenant
+status. The tenant prefix may be left out, so "dev" selects "<tenant>-dev".
+
+Without a namespace, a picker is shown when running in a terminal; otherwise
+the namespaces of the Tenant are listed with the current one marked.`,
+		Example: `  # Switch to my-tenant-dev
+  kubectl tenant ns my-tenant dev
+
+  # Pick a namespace of my-tenant interactively
+  kubectl tenant ns my-tenant`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			tenant, err := getTenant(cmd.Context(), dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			names := extractNamespaceNames(tenant)
+			if len(names) == 0 {
+				return fmt.Errorf("tenant %q has no namespaces", tenantName)
+			}
+
+			if len(args) == 2 {
+				namespace, err := resolveTenantNamespace(names, tenantName, args[1])
+				if err != nil {
+					return err
+				}
+				return switchContextNamespace(configFlags, namespace, ioStreams.Out)
+			}
+
+			raw, contextName, err := currentKubeContext(configFlags)
+			if err != nil {
+				return err
+			}
+			current := raw.Contexts[contextName].Namespace
+
+			if !isTerminal(ioStreams.In) {
+				return printNamespaceChoices(ioStreams.Out, names, current)
+			}
+			namespace, err := pickNamespace(ioStreams, names, current)
+			if err != nil {
+				return err
+			}
+			return switchContextNamespace(configFlags, namespace, ioStreams.Out)
+		},
+	}
+
+	return cmd
+}
+
+// resolveTenantNamespace finds name among the tenant namespaces, either as given
+// or with the "<tenant>-" prefix the operator adds.
+func resolveTenantNamespace(names []string, tenantName, name string) (string, error) {
+	for _, candidate := range []string{name, tenantName + "-" + name} {
+		if slices.Contains(names, candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("namespace %q is not a namespace of tenant %q", name, tenantName)
+}
+
+func isTerminal(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// printNamespaceChoices lists the namespaces, marking the current one with "*".
+func printNamespaceChoices(out io.Writer, names []string, current string) error {
+	var b strings.Builder
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %s\n", marker, name)
+	}
+	if _, err := io.WriteString(out, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// pickNamespace shows a numbered menu of the namespaces and reads the choice,
+// by number or by name, until a valid one is entered.
+func pickNamespace(ioStreams genericiooptions.IOStreams, names []string, current string) (string, error) {
+	var b strings.Builder
+	for i, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		fmt.Fprintf(&b, "%s %2d) %s\n", marker, i+1, name)
+	}
+	if _, err := io.WriteString(ioStreams.ErrOut, b.String()); err != nil {
+		return "", fmt.Errorf("failed to write output: %w", err)
+	}
+
+	reader := bufio.NewReader(ioStreams.In)
+	for {
+		if _, err := fmt.Fprintf(ioStreams.ErrOut, "Namespace [1-%d]: ", len(names)); err != nil {
+			return "", fmt.Errorf("failed to write output: %w", err)
+		}
+		line, err := reader.ReadString('\n')
+		choice := strings.TrimSpace(line)
+		if choice != "" {
+			if i, convErr := strconv.Atoi(choice); convErr == nil && i >= 1 && i <= len(names) {
+				return names[i-1], nil
+			}
+			if slices.Contains(names, choice) {
+				return choice, nil
+			}
+			_, _ = fmt.Fprintf(ioStreams.ErrOut, "%q is not one of the listed namespaces\n", choice)
+		}
+		if err != nil {
+			if err == io.EOF {
+				return "", fmt.Errorf("no namespace selected")
+			}
+			return "", err
+		}
+	}
+}
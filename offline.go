@@ -0,0 +1,359 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+// resolveCluster makes --resolve look the permitted names up in the live cluster.
+const resolveCluster = "cluster"
+
+// offlineListKinds are the resources served by the in-memory client of offline mode.
+var offlineListKinds = map[schema.GroupVersionResource]string{
+	tenantGVR:            "TenantList",
+	resourceQuotaGVR:     "ResourceQuotaList",
+	limitRangeGVR:        "LimitRangeList",
+	selfSubjectReviewGVR: "SelfSubjectReviewList",
+}
+
+func init() {
+	for _, opts := range ClusterResources {
+		offlineListKinds[opts.resource] = opts.listKind
+	}
+}
+
+// offlineOptions evaluate commands against Tenant manifests instead of the
+// Tenants in the cluster, for reviewing changes before they are applied.
+type offlineOptions struct {
+	manifests manifestOptions
+	resolve   string
+
+	// tenants are read from the manifests by dynamicClient
+	tenants []*unstructured.Unstructured
+}
+
+func (o *offlineOptions) addFlags(cmd *cobra.Command) {
+	o.manifests.addFlags(cmd,
+		"Files or directories with Tenant manifests to evaluate instead of the Tenants in the cluster, or - for stdin")
+	cmd.Flags().StringVar(&o.resolve, "resolve", "",
+		`With -f, look the permitted objects up in the cluster ("cluster") or in a directory of exported objects`)
+}
+
+func (o *offlineOptions) active() bool {
+	return len(o.manifests.filenames) > 0
+}
+
+// readsCluster reports whether the objects besides the Tenants, like the
+// ResourceQuotas and LimitRanges of the namespaces, are read from the cluster.
+func (o *offlineOptions) readsCluster() bool {
+	return !o.active() || o.resolve == resolveCluster
+}
+
+// tenantNames returns the names of the Tenants in the manifests.
+func (o *offlineOptions) tenantNames() []string {
+	names := make([]string, 0, len(o.tenants))
+	for _, tenant := range o.tenants {
+		names = append(names, tenant.GetName())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dynamicClient returns the client Tenants and cluster objects are read with.
+//
+// Without -f this is the cluster. With -f the Tenants are served from the manifests,
+// with a status derived from their spec, and the permitted objects from --resolve.
+// Objects in the manifests take precedence over those of a --resolve directory,
+// and are ignored with --resolve cluster. Without --resolve no cluster is needed:
+// every permitted name is served as an object that has nothing but its name,
+// unless the manifests contain the object.
+func (o *offlineOptions) dynamicClient(
+	ctx context.Context,
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) (dynamic.Interface, error) {
+	if !o.active() {
+		if o.resolve != "" {
+			return nil, errors.New("--resolve can only be used with -f")
+		}
+		cfg, err := configFlags.ToRESTConfig()
+		if err != nil {
+			return nil, err
+		}
+		return dynamic.NewForConfig(cfg)
+	}
+
+	objs, err := o.manifests.read(ioStreams.In)
+	if err != nil {
+		return nil, err
+	}
+	var others []*unstructured.Unstructured
+	for _, obj := range objs {
+		if isTenantManifest(obj) {
+			o.tenants = append(o.tenants, obj)
+		} else {
+			others = append(others, obj)
+		}
+	}
+	if len(o.tenants) == 0 {
+		return nil, errors.New("no Tenant found in the manifests")
+	}
+
+	// base serves everything but the Tenants; nil when the names aren't resolved
+	var base dynamic.Interface
+	switch o.resolve {
+	case "":
+	case resolveCluster:
+		cfg, err := configFlags.ToRESTConfig()
+		if err != nil {
+			return nil, err
+		}
+		if base, err = dynamic.NewForConfig(cfg); err != nil {
+			return nil, err
+		}
+	default:
+		if info, err := os.Stat(o.resolve); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("--resolve must be %q or a directory: %q is not a directory", resolveCluster, o.resolve)
+		}
+		exported := &manifestOptions{filenames: []string{o.resolve}, recursive: true}
+		objs, err := exported.read(nil)
+		if err != nil {
+			return nil, err
+		}
+		// Objects next to the Tenants replace the exported ones
+		base = newOfflineClient(append(objs, others...))
+	}
+	if o.resolve == resolveCluster && len(others) > 0 {
+		_, _ = fmt.Fprintf(ioStreams.ErrOut,
+			"Warning: %d objects in the manifests besides Tenants are ignored with --resolve %s\n", len(others), resolveCluster)
+	}
+
+	var served []*unstructured.Unstructured
+	for i, tenant := range o.tenants {
+		tenant = tenant.DeepCopy()
+		if err := deriveTenantStatus(ctx, tenant, base, ioStreams.ErrOut); err != nil {
+			return nil, err
+		}
+		o.tenants[i] = tenant
+		served = append(served, tenant)
+		if base == nil {
+			served = append(served, permittedStubs(tenant)...)
+		}
+	}
+	if base == nil {
+		// Objects next to the Tenants, like their Quota, replace the stubs
+		served = append(served, others...)
+	}
+
+	tenants := newOfflineClient(served)
+	if base == nil {
+		return tenants, nil
+	}
+	return &tenantOverlay{Interface: base, tenants: tenants}, nil
+}
+
+// isTenantManifest reports whether obj is a Tenant, of any served version.
+func isTenantManifest(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind() == schema.GroupKind{Group: tenantGVR.Group, Kind: "Tenant"}
+}
+
+// newOfflineClient serves objs from memory. Objects of resources the commands
+// don't read are left out.
+func newOfflineClient(objs []*unstructured.Unstructured) dynamic.Interface {
+	kinds := map[schema.GroupKind]bool{}
+	for gvr, listKind := range offlineListKinds {
+		kinds[schema.GroupKind{Group: gvr.Group, Kind: strings.TrimSuffix(listKind, "List")}] = true
+	}
+
+	// The tracker refuses duplicates; the last object of a name wins
+	var known []runtime.Object
+	index := map[string]int{}
+	for _, obj := range objs {
+		gk := obj.GroupVersionKind().GroupKind()
+		if !kinds[gk] {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s", gk, obj.GetNamespace(), obj.GetName())
+		if i, ok := index[key]; ok {
+			known[i] = obj
+			continue
+		}
+		index[key] = len(known)
+		known = append(known, obj)
+	}
+	return fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), offlineListKinds, known...)
+}
+
+// tenantOverlay serves the Tenants of the manifests and everything else from
+// the underlying client.
+type tenantOverlay struct {
+	dynamic.Interface
+	tenants dynamic.Interface
+}
+
+func (c *tenantOverlay) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	if resource == tenantGVR {
+		return c.tenants.Resource(resource)
+	}
+	return c.Interface.Resource(resource)
+}
+
+// deriveTenantStatus replaces the status of a Tenant manifest with the one the
+// operator would publish for its spec: the allowed classes, the quota and the
+// namespaces. spec.<field>.allowedRegex is matched against the names in base; it
+// can't be evaluated without base.
+func deriveTenantStatus(
+	ctx context.Context,
+	tenant *unstructured.Unstructured,
+	base dynamic.Interface,
+	warnOut io.Writer,
+) error {
+	status := map[string]interface{}{}
+
+	for _, resourceType := range sortedClusterResources() {
+		opts := ClusterResources[resourceType]
+		if opts.allowListField == "" {
+			continue
+		}
+		names, _, _ := unstructured.NestedStringSlice(tenant.Object, "spec", opts.allowListField, "allowed")
+
+		pattern, _, _ := unstructured.NestedString(tenant.Object, "spec", opts.allowListField, "allowedRegex")
+		if pattern != "" {
+			matched, err := matchAllowedRegex(ctx, base, opts, pattern)
+			if err != nil {
+				return fmt.Errorf("tenant %q: spec.%s.allowedRegex: %w", tenant.GetName(), opts.allowListField, err)
+			}
+			if base == nil {
+				_, _ = fmt.Fprintf(warnOut, "Warning: spec.%s.allowedRegex of tenant %q is ignored without --resolve\n",
+					opts.allowListField, tenant.GetName())
+			}
+			names = append(names, matched...)
+		}
+		status[opts.statusField] = availableNames(names)
+	}
+
+	if quota, _, _ := unstructured.NestedString(tenant.Object, "spec", "quota"); quota != "" {
+		status[ClusterResources["quotas"].statusField] = availableNames([]string{quota})
+	}
+
+	var namespaces []interface{}
+	withPrefix, _, _ := unstructured.NestedStringSlice(tenant.Object, "spec", "namespaces", "withTenantPrefix")
+	for _, ns := range withPrefix {
+		namespaces = append(namespaces, tenant.GetName()+"-"+ns)
+	}
+	withoutPrefix, _, _ := unstructured.NestedStringSlice(tenant.Object, "spec", "namespaces", "withoutTenantPrefix")
+	for _, ns := range withoutPrefix {
+		namespaces = append(namespaces, ns)
+	}
+	status["deployedNamespaces"] = namespaces
+
+	if enabled, _, _ := unstructured.NestedBool(tenant.Object, "spec", "namespaces", "sandboxes", "enabled"); enabled {
+		sandboxes := map[string]interface{}{}
+		for _, m := range extractMembers(tenant) {
+			if m.Kind == "User" && m.Role != "viewer" {
+				sandboxes[m.Name] = sandboxNamespaceName(tenant.GetName(), m.Name)
+			}
+		}
+		status["deployedSandboxes"] = sandboxes
+	}
+
+	tenant.Object["status"] = status
+	return nil
+}
+
+// matchAllowedRegex returns the names of the objects in base that match pattern.
+func matchAllowedRegex(ctx context.Context, base dynamic.Interface, opts getOptions, pattern string) ([]string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil {
+		return nil, nil
+	}
+	list, err := base.Resource(opts.resource).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list %s: %w", opts.resource.Resource, err)
+	}
+	var out []string
+	for _, item := range list.Items {
+		if re.MatchString(item.GetName()) {
+			out = append(out, item.GetName())
+		}
+	}
+	return out, nil
+}
+
+// availableNames builds a status.<field> with the names as its available entries.
+func availableNames(names []string) map[string]interface{} {
+	available := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		available = append(available, map[string]interface{}{"name": name})
+	}
+	return map[string]interface{}{"available": available}
+}
+
+// sandboxNamespaceName approximates the sandbox namespace the operator creates
+// for user: the user name lowercased, with anything but letters, digits and
+// dashes replaced by dashes.
+func sandboxNamespaceName(tenantName, user string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(user))
+	return fmt.Sprintf("%s-%s-sandbox", tenantName, sanitized)
+}
+
+// permittedStubs returns an object with only a name for everything the tenant is
+// permitted, so the commands can list the names without a cluster.
+func permittedStubs(tenant *unstructured.Unstructured) []*unstructured.Unstructured {
+	var out []*unstructured.Unstructured
+	for _, resourceType := range sortedClusterResources() {
+		opts := ClusterResources[resourceType]
+		for _, name := range opts.extractTenantResources(tenant) {
+			stub := &unstructured.Unstructured{}
+			stub.SetAPIVersion(opts.resource.GroupVersion().String())
+			stub.SetKind(strings.TrimSuffix(opts.listKind, "List"))
+			stub.SetName(name)
+			out = append(out, stub)
+		}
+	}
+	return out
+}
+
+// isPermittedStub reports whether obj is an object of permittedStubs, which has
+// nothing but its name.
+func isPermittedStub(obj *unstructured.Unstructured) bool {
+	for field := range obj.Object {
+		if field != "apiVersion" && field != "kind" && field != "metadata" {
+			return false
+		}
+	}
+	return len(obj.GetLabels()) == 0 && len(obj.GetAnnotations()) == 0
+}
+
+func sortedClusterResources() []string {
+	out := make([]string, 0, len(ClusterResources))
+	for resourceType := range ClusterResources {
+		out = append(out, resourceType)
+	}
+	sort.Strings(out)
+	return out
+}
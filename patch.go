@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+)
+
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// updateTenantSpec lets mutate edit a copy of the Tenant's spec.<fields...> map and
+// writes the result back with a JSON patch pinned to the resourceVersion it was read at.
+// Conflicts with concurrent writers are retried against a fresh copy of the Tenant.
+// The returned maps hold the field before and after the update; after reflects the
+// server's response unless dryRun is client-side.
+func updateTenantSpec(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantName string,
+	fields []string,
+	dryRun cmdutil.DryRunStrategy,
+	mutate func(map[string]interface{}) error,
+) (before, after map[string]interface{}, err error) {
+	path := append([]string{"spec"}, fields...)
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		tenant, err := getTenant(ctx, dyn, tenantName, nil)
+		if err != nil {
+			return err
+		}
+
+		current, _, err := unstructured.NestedMap(tenant.Object, path...)
+		if err != nil {
+			return fmt.Errorf("read %s of tenant %q: %w", strings.Join(path, "."), tenantName, err)
+		}
+		if current == nil {
+			current = map[string]interface{}{}
+		}
+		before = current
+
+		updated := runtime.DeepCopyJSON(current)
+		if err := mutate(updated); err != nil {
+			return err
+		}
+		after = updated
+
+		if reflect.DeepEqual(before, after) || dryRun == cmdutil.DryRunClient {
+			return nil
+		}
+
+		patch, err := json.Marshal([]jsonPatchOp{
+			{Op: "replace", Path: "/metadata/resourceVersion", Value: tenant.GetResourceVersion()},
+			setFieldOp(tenant.Object, path, updated),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal patch: %w", err)
+		}
+
+		patchOpts := metav1.PatchOptions{}
+		if dryRun == cmdutil.DryRunServer {
+			patchOpts.DryRun = []string{metav1.DryRunAll}
+		}
+		patched, err := dyn.Resource(tenantGVR).Patch(ctx, tenantName, types.JSONPatchType, patch, patchOpts)
+		if err != nil {
+			return err
+		}
+
+		after, _, _ = unstructured.NestedMap(patched.Object, path...)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("update tenant %q: %w", tenantName, err)
+	}
+	return before, after, nil
+}
+
+// setFieldOp returns the JSON patch operation that sets the field at path to value,
+// creating the first missing parent object in the same operation.
+func setFieldOp(obj map[string]interface{}, path []string, value interface{}) jsonPatchOp {
+	for i := range path[:len(path)-1] {
+		if _, found, _ := unstructured.NestedFieldNoCopy(obj, path[:i+1]...); found {
+			continue
+		}
+		nested := value
+		for j := len(path) - 1; j > i; j-- {
+			nested = map[string]interface{}{path[j]: nested}
+		}
+		return jsonPatchOp{Op: "add", Path: jsonPointer(path[:i+1]), Value: nested}
+	}
+	return jsonPatchOp{Op: "add", Path: jsonPointer(path), Value: value}
+}
+
+func jsonPointer(path []string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	var b strings.Builder
+	for _, p := range path {
+		b.WriteString("/")
+		b.WriteString(escaper.Replace(p))
+	}
+	return b.String()
+}
+
+// printFieldDiff writes a unified diff of a Tenant field before and after an update,
+// both rendered as YAML under the given key.
+func printFieldDiff(out io.Writer, key string, before, after interface{}) error {
+	from, err := yaml.Marshal(map[string]interface{}{key: before})
+	if err != nil {
+		return err
+	}
+	to, err := yaml.Marshal(map[string]interface{}{key: after})
+	if err != nil {
+		return err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(from)),
+		B:        difflib.SplitLines(string(to)),
+		FromFile: "before",
+		ToFile:   "after",
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(out, diff)
+	return err
+}
+
+// printPatchResult reports the outcome of a Tenant update in kubectl's style.
+func printPatchResult(
+	out io.Writer,
+	tenantName string,
+	before, after interface{},
+	dryRun cmdutil.DryRunStrategy,
+) error {
+	result := "patched"
+	if reflect.DeepEqual(before, after) {
+		result = "unchanged"
+	}
+	switch dryRun {
+	case cmdutil.DryRunClient:
+		result += " (dry run)"
+	case cmdutil.DryRunServer:
+		result += " (server dry run)"
+	}
+	if _, err := fmt.Fprintf(out, "%s/%s %s\n", tenantGVR.GroupResource().String(), tenantName, result); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
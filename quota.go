@@ -0,0 +1,566 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	resourcehelper "k8s.io/kubectl/pkg/util/resource"
+	"sigs.k8s.io/yaml"
+)
+
+var resourceQuotaGVR = schema.GroupVersionResource{Version: "v1", Resource: "resourcequotas"}
+
+// tenantQuota is the Quota CR of a tenant with the ResourceQuota usage of each
+// tenant namespace.
+type tenantQuota struct {
+	tenant     string
+	name       string
+	quota      *unstructured.Unstructured
+	hard       corev1.ResourceList
+	namespaces []string
+	used       map[string]corev1.ResourceList
+}
+
+// resourceUsage is one row of the quota usage report.
+type resourceUsage struct {
+	Resource   string                       `json:"resource"`
+	Used       resource.Quantity            `json:"used"`
+	Hard       resource.Quantity            `json:"hard"`
+	Percent    float64                      `json:"percent"`
+	Namespaces map[string]resource.Quantity `json:"namespaces,omitempty"`
+}
+
+type quotaUsageReport struct {
+	Tenant    string          `json:"tenant"`
+	Quota     string          `json:"quota"`
+	Resources []resourceUsage `json:"resources"`
+}
+
+// podSpecPaths locate the pod spec of the workload kinds quota check understands.
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// replicaPaths locate the number of pods a workload runs; it defaults to 1.
+var replicaPaths = map[string][]string{
+	"Deployment":            {"spec", "replicas"},
+	"StatefulSet":           {"spec", "replicas"},
+	"ReplicaSet":            {"spec", "replicas"},
+	"ReplicationController": {"spec", "replicas"},
+	"Job":                   {"spec", "parallelism"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "parallelism"},
+}
+
+// standardQuotaResources may be limited by ResourceQuotas without the "requests." prefix.
+var standardQuotaResources = []corev1.ResourceName{
+	corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage,
+}
+
+// workload is a manifest that runs pods.
+type workload struct {
+	obj      *unstructured.Unstructured
+	replicas int64
+	pod      corev1.Pod
+}
+
+// quotaFit is one row of the quota check report.
+type quotaFit struct {
+	Resource  string            `json:"resource"`
+	Used      resource.Quantity `json:"used"`
+	Requested resource.Quantity `json:"requested"`
+	Total     resource.Quantity `json:"total"`
+	Hard      resource.Quantity `json:"hard"`
+	Exceeded  bool              `json:"exceeded"`
+}
+
+func newQuotaCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "quota",
+		Short: "Inspect the quota of a Tenant",
+		Long: `Inspect the quota of a Tenant.
+
+The hard limits come from spec.resourcequota.hard of the Quota CR the Tenant
+uses; the usage is read from the status of the ResourceQuotas in the tenant
+namespaces.`,
+	}
+
+	cmd.AddCommand(newQuotaUsageCmd(configFlags, ioStreams))
+	cmd.AddCommand(newQuotaCheckCmd(configFlags, ioStreams))
+
+	return cmd
+}
+
+func newQuotaUsageCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "usage <tenant>",
+		Short: "Show how much of its quota a Tenant uses",
+		Long: `Show how much of its quota a Tenant uses.
+
+The used values of the ResourceQuotas in all tenant namespaces are summed and
+compared to the hard limits of the tenant's Quota CR. With -o wide the usage
+of each namespace is shown as well.`,
+		Example: `  # Show the quota usage of my-tenant
+  kubectl tenant quota usage my-tenant
+
+  # Break the usage down per namespace
+  kubectl tenant quota usage my-tenant -o wide`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != "wide" && output != "json" && output != "yaml" {
+				return fmt.Errorf("unsupported output format %q: must be one of wide, json, yaml", output)
+			}
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			q, err := loadTenantQuota(cmd.Context(), dyn, args[0], ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			return printQuotaUsage(ioStreams.Out, output, q.usageReport())
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: wide, json or yaml")
+
+	return cmd
+}
+
+func newQuotaCheckCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var output string
+	manifests := &manifestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "check <tenant> -f <manifests>",
+		Short: "Check whether workloads fit the remaining quota of a Tenant",
+		Long: `Check whether workloads fit the remaining quota of a Tenant.
+
+The container requests and limits of every Pod, Deployment, StatefulSet,
+ReplicaSet, ReplicationController, DaemonSet, Job and CronJob in the manifests
+are multiplied by their replicas, added to the current ResourceQuota usage of
+the tenant namespaces, and compared to the hard limits of the tenant's Quota CR.
+Workloads are counted as new, even if they are already deployed. DaemonSets
+are counted as a single pod.
+
+The command exits with a non-zero status when a limit would be exceeded.`,
+		Example: `  # Check a directory of manifests before deploying
+  kubectl tenant quota check my-tenant -f manifests/
+
+  # Check rendered manifests from stdin
+  helm template my-app ./chart | kubectl tenant quota check my-tenant -f -`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+			if output != "" && output != "json" && output != "yaml" {
+				return fmt.Errorf("unsupported output format %q: must be one of json, yaml", output)
+			}
+
+			objs, err := manifests.read(ioStreams.In)
+			if err != nil {
+				return err
+			}
+			workloads, err := extractWorkloads(objs)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			q, err := loadTenantQuota(cmd.Context(), dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			requested := corev1.ResourceList{}
+			for _, w := range workloads {
+				if ns := w.obj.GetNamespace(); ns != "" && !slices.Contains(q.namespaces, ns) {
+					_, _ = fmt.Fprintf(ioStreams.ErrOut, "Warning: %s %q is in namespace %q, which is not a namespace of tenant %q\n",
+						w.obj.GetKind(), w.obj.GetName(), ns, tenantName)
+				}
+				if w.obj.GetKind() == "DaemonSet" {
+					_, _ = fmt.Fprintf(ioStreams.ErrOut, "Warning: DaemonSet %q runs a pod per node; it is counted as one pod\n",
+						w.obj.GetName())
+				}
+				addResources(requested, w.quotaUsage())
+			}
+
+			fits := q.fit(requested)
+			if err := printQuotaFit(ioStreams.Out, output, fits); err != nil {
+				return err
+			}
+
+			var exceeded []string
+			for _, f := range fits {
+				if f.Exceeded {
+					exceeded = append(exceeded, f.Resource)
+				}
+			}
+			if len(exceeded) > 0 {
+				return fmt.Errorf("the manifests exceed quota %q of tenant %q for %s",
+					q.name, tenantName, strings.Join(exceeded, ", "))
+			}
+			return nil
+		},
+	}
+
+	manifests.addFlags(cmd, "Files or directories with the workloads to check, or - for stdin")
+	_ = cmd.MarkFlagRequired("filename")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json or yaml")
+
+	return cmd
+}
+
+// loadTenantQuota reads the Quota CR of the tenant and the ResourceQuota usage of
+// every tenant namespace.
+func loadTenantQuota(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantName string,
+	warnOut io.Writer,
+) (*tenantQuota, error) {
+	tenant, err := getTenant(ctx, dyn, tenantName, warnOut)
+	if err != nil {
+		return nil, err
+	}
+
+	quota, err := getTenantQuota(ctx, dyn, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if quota == nil {
+		return nil, fmt.Errorf("tenant %q has no quota", tenantName)
+	}
+	hard, err := quotaHard(quota)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &tenantQuota{
+		tenant:     tenantName,
+		name:       quota.GetName(),
+		quota:      quota,
+		hard:       hard,
+		namespaces: extractNamespaceNames(tenant),
+		used:       map[string]corev1.ResourceList{},
+	}
+	for _, ns := range q.namespaces {
+		if q.used[ns], err = namespaceQuotaUsage(ctx, dyn, ns); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// getTenantQuota reads the Quota CR the tenant uses, or returns nil when it has none.
+func getTenantQuota(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenant *unstructured.Unstructured,
+) (*unstructured.Unstructured, error) {
+	name := tenantQuotaName(tenant)
+	if name == "" {
+		return nil, nil
+	}
+	quota, err := dyn.Resource(ClusterResources["quotas"].resource).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get quota %q: %w", name, err)
+	}
+	return quota, nil
+}
+
+// tenantQuotaName returns the quota published in the Tenant status, or the one
+// requested in the spec before the operator has reconciled it.
+func tenantQuotaName(tenant *unstructured.Unstructured) string {
+	if names := extractQuotaNames(tenant); len(names) > 0 {
+		return names[0]
+	}
+	name, _, _ := unstructured.NestedString(tenant.Object, "spec", "quota")
+	return name
+}
+
+// quotaHard parses spec.resourcequota.hard of a Quota CR.
+func quotaHard(quota *unstructured.Unstructured) (corev1.ResourceList, error) {
+	values, _, err := unstructured.NestedMap(quota.Object, "spec", "resourcequota", "hard")
+	if err != nil {
+		return nil, fmt.Errorf("read hard limits of quota %q: %w", quota.GetName(), err)
+	}
+	hard := corev1.ResourceList{}
+	for name, value := range values {
+		q, err := resource.ParseQuantity(fmt.Sprint(value))
+		if err != nil {
+			return nil, fmt.Errorf("quota %q: invalid hard limit %s=%v: %w", quota.GetName(), name, value, err)
+		}
+		hard[corev1.ResourceName(name)] = q
+	}
+	return hard, nil
+}
+
+// namespaceQuotaUsage returns the usage of a namespace from the status.used of
+// its ResourceQuotas. Every quota reports the usage of the whole namespace for
+// the resources it tracks, so quotas tracking the same resource aren't summed.
+func namespaceQuotaUsage(ctx context.Context, dyn dynamic.Interface, namespace string) (corev1.ResourceList, error) {
+	list, err := dyn.Resource(resourceQuotaGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list resourcequotas in namespace %q: %w", namespace, err)
+	}
+
+	used := corev1.ResourceList{}
+	for _, item := range list.Items {
+		var rq corev1.ResourceQuota
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &rq); err != nil {
+			return nil, fmt.Errorf("read resourcequota %s/%s: %w", namespace, item.GetName(), err)
+		}
+		for name, q := range rq.Status.Used {
+			if current, ok := used[name]; !ok || q.Cmp(current) > 0 {
+				used[name] = q
+			}
+		}
+	}
+	return used, nil
+}
+
+// addResources adds the quantities of add to total.
+func addResources(total, add corev1.ResourceList) {
+	for name, q := range add {
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+}
+
+// totalUsed sums the usage of all tenant namespaces.
+func (q *tenantQuota) totalUsed() corev1.ResourceList {
+	total := corev1.ResourceList{}
+	for _, used := range q.used {
+		addResources(total, used)
+	}
+	return total
+}
+
+func (q *tenantQuota) usageReport() quotaUsageReport {
+	report := quotaUsageReport{Tenant: q.tenant, Quota: q.name}
+	total := q.totalUsed()
+
+	for _, name := range sortedResourceNames(q.hard) {
+		row := resourceUsage{
+			Resource:   string(name),
+			Used:       total[name],
+			Hard:       q.hard[name],
+			Percent:    usagePercent(total[name], q.hard[name]),
+			Namespaces: map[string]resource.Quantity{},
+		}
+		for _, ns := range q.namespaces {
+			row.Namespaces[ns] = q.used[ns][name]
+		}
+		report.Resources = append(report.Resources, row)
+	}
+	return report
+}
+
+func sortedResourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func usagePercent(used, hard resource.Quantity) float64 {
+	if hard.IsZero() {
+		return 0
+	}
+	return used.AsApproximateFloat64() / hard.AsApproximateFloat64() * 100
+}
+
+func printQuotaUsage(out io.Writer, format string, report quotaUsageReport) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(report)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	var namespaces []string
+	if format == "wide" && len(report.Resources) > 0 {
+		for ns := range report.Resources[0].Namespaces {
+			namespaces = append(namespaces, ns)
+		}
+		sort.Strings(namespaces)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	header := append([]string{"RESOURCE", "USED", "HARD", "USAGE"}, namespaces...)
+	if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, r := range report.Resources {
+		cells := []string{r.Resource, r.Used.String(), r.Hard.String(), fmt.Sprintf("%.0f%%", r.Percent)}
+		for _, ns := range namespaces {
+			used := r.Namespaces[ns]
+			cells = append(cells, used.String())
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// extractWorkloads reads the pod template and replicas of the objects that run
+// pods; other objects are skipped.
+func extractWorkloads(objs []*unstructured.Unstructured) ([]workload, error) {
+	var out []workload
+	for _, obj := range objs {
+		path, ok := podSpecPaths[obj.GetKind()]
+		if !ok {
+			continue
+		}
+
+		w := workload{obj: obj, replicas: 1}
+		spec, _, err := unstructured.NestedMap(obj.Object, path...)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &w.pod.Spec); err != nil {
+			return nil, fmt.Errorf("%s %q: invalid pod template: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if replicaPath, ok := replicaPaths[obj.GetKind()]; ok {
+			replicas, found, err := unstructured.NestedInt64(obj.Object, replicaPath...)
+			if err != nil {
+				return nil, fmt.Errorf("%s %q: %w", obj.GetKind(), obj.GetName(), err)
+			}
+			if found {
+				w.replicas = replicas
+			}
+		}
+		out = append(out, w)
+	}
+	return out, nil
+}
+
+// quotaUsage returns what the pods of the workload count against a ResourceQuota.
+func (w workload) quotaUsage() corev1.ResourceList {
+	requests, limits := resourcehelper.PodRequestsAndLimits(&w.pod)
+
+	usage := corev1.ResourceList{corev1.ResourcePods: *resource.NewQuantity(1, resource.DecimalSI)}
+	for name, q := range requests {
+		usage[corev1.ResourceName("requests."+string(name))] = q
+		if slices.Contains(standardQuotaResources, name) {
+			usage[name] = q
+		}
+	}
+	for name, q := range limits {
+		usage[corev1.ResourceName("limits."+string(name))] = q
+	}
+
+	for name, q := range usage {
+		q.Mul(w.replicas)
+		usage[name] = q
+	}
+	return usage
+}
+
+// fit adds requested to the current usage of every hard limited resource.
+func (q *tenantQuota) fit(requested corev1.ResourceList) []quotaFit {
+	used := q.totalUsed()
+
+	var out []quotaFit
+	for _, name := range sortedResourceNames(q.hard) {
+		total := used[name].DeepCopy()
+		total.Add(requested[name])
+		out = append(out, quotaFit{
+			Resource:  string(name),
+			Used:      used[name],
+			Requested: requested[name],
+			Total:     total,
+			Hard:      q.hard[name],
+			Exceeded:  total.Cmp(q.hard[name]) > 0,
+		})
+	}
+	return out
+}
+
+func printQuotaFit(out io.Writer, format string, fits []quotaFit) error {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(fits, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(fits)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "RESOURCE\tUSED\tREQUESTED\tTOTAL\tHARD\tSTATUS"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, f := range fits {
+		status := "ok"
+		if f.Exceeded {
+			status = "EXCEEDED"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			f.Resource, f.Used.String(), f.Requested.String(), f.Total.String(), f.Hard.String(), status); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
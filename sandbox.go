@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/cmd/get"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+// sandboxFields is the path of the sandbox configuration below the Tenant spec.
+var sandboxFields = []string{"namespaces", "sandboxes"}
+
+func newSandboxCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sandbox",
+		Short: "Manage sandbox namespaces of a Tenant",
+		Long: `Manage sandbox namespaces of a Tenant.
+
+The operator creates a sandbox namespace for every owner and editor of a Tenant
+while spec.namespaces.sandboxes.enabled is set, and publishes them in
+status.deployedSandboxes keyed by user.`,
+	}
+
+	cmd.AddCommand(newSandboxCreateCmd(configFlags, ioStreams))
+	cmd.AddCommand(newSandboxDeleteCmd(configFlags, ioStreams))
+	cmd.AddCommand(newSandboxListCmd(configFlags, ioStreams))
+
+	return cmd
+}
+
+func newSandboxCreateCmd(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	var wait, switchNamespace bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "create <tenant>",
+		Short: "Request a sandbox namespace for the current user",
+		Long: `Request a sandbox namespace for the current user.
+
+This enables spec.namespaces.sandboxes of the Tenant CR and waits until the
+sandbox of the current user appears in status.deployedSandboxes. With --switch,
+the namespace of the current kubeconfig context is set to the sandbox.`,
+		Example: `  # Create a sandbox and start working in it
+  kubectl tenant sandbox create my-tenant --switch
+
+  # Preview the change without persisting it
+  kubectl tenant sandbox create my-tenant --dry-run=server`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+			if switchNamespace && !wait {
+				return fmt.Errorf("--switch requires --wait")
+			}
+
+			dryRun, err := cmdutil.GetDryRunStrategy(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			user, err := currentUser(ctx, dyn)
+			if err != nil {
+				return err
+			}
+			tenant, err := getTenant(ctx, dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			if !isSandboxMember(tenant, user) {
+				_, _ = fmt.Fprintf(ioStreams.ErrOut,
+					"Warning: user %q is not an owner or editor of tenant %q; the operator may not create a sandbox\n",
+					user, tenantName)
+			}
+
+			if err := updateSandboxes(cmd, dyn, tenantName, true, dryRun, ioStreams); err != nil {
+				return err
+			}
+			if !wait || dryRun != cmdutil.DryRunNone {
+				return nil
+			}
+
+			namespace, err := waitForSandbox(ctx, dyn, tenantName, user, true, timeout)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(ioStreams.Out, "sandbox namespace %q is ready\n", namespace); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+
+			if switchNamespace {
+				return switchContextNamespace(configFlags, namespace, ioStreams.Out)
+			}
+			return nil
+		},
+	}
+
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().BoolVar(&wait, "wait", true, "Wait until the sandbox appears in the Tenant status")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait for the Tenant status")
+	cmd.Flags().BoolVar(&switchNamespace, "switch", false,
+		"Set the namespace of the current kubeconfig context to the sandbox")
+
+	return cmd
+}
+
+func newSandboxDeleteCmd(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	var wait, all bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "delete <tenant>",
+		Short: "Remove the sandbox namespace of the current user",
+		Long: `Remove the sandbox namespace of the current user.
+
+Sandboxes are enabled for a whole Tenant, so this disables
+spec.namespaces.sandboxes of the Tenant CR. When other users still have a
+sandbox, they would lose it too, and --all is required to confirm.`,
+		Example: `  # Remove your sandbox
+  kubectl tenant sandbox delete my-tenant
+
+  # Remove the sandboxes of all users of the tenant
+  kubectl tenant sandbox delete my-tenant --all`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+
+			dryRun, err := cmdutil.GetDryRunStrategy(cmd)
+			if err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			user, err := currentUser(ctx, dyn)
+			if err != nil {
+				return err
+			}
+			tenant, err := getTenant(ctx, dyn, tenantName, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+
+			var others []string
+			for _, ns := range extractTenantNamespaces(tenant) {
+				if ns.kind == namespaceTypeSandbox && ns.owner != user {
+					others = append(others, ns.owner)
+				}
+			}
+			if len(others) > 0 && !all {
+				return fmt.Errorf("disabling sandboxes of tenant %q also deletes the sandboxes of %s; use --all to confirm",
+					tenantName, strings.Join(others, ", "))
+			}
+
+			if err := updateSandboxes(cmd, dyn, tenantName, false, dryRun, ioStreams); err != nil {
+				return err
+			}
+			if !wait || dryRun != cmdutil.DryRunNone {
+				return nil
+			}
+
+			_, err = waitForSandbox(ctx, dyn, tenantName, user, false, timeout)
+			return err
+		},
+	}
+
+	cmdutil.AddDryRunFlag(cmd)
+	cmd.Flags().BoolVar(&wait, "wait", true, "Wait until the sandbox is gone from the Tenant status")
+	cmd.Flags().DurationVar(&timeout, "timeout", 2*time.Minute, "How long to wait for the Tenant status")
+	cmd.Flags().BoolVar(&all, "all", false, "Confirm deleting the sandboxes of the other users of the tenant")
+
+	return cmd
+}
+
+func newSandboxListCmd(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	filter := &listFilter{namespaces: namespaceFilter{sandboxesOnly: true}}
+	printFlags := get.NewGetPrintFlags()
+
+	cmd := &cobra.Command{
+		Use:   "list <tenant>",
+		Short: "List the sandbox namespaces of a Tenant",
+		Long: `List the sandbox namespaces of a Tenant with their owners.
+
+This is the same as 'kubectl tenant get namespaces <tenant> --sandboxes-only'.`,
+		Example: `  # List all sandboxes of my-tenant
+  kubectl tenant sandbox list my-tenant
+
+  # Show only your own sandbox
+  kubectl tenant sandbox list my-tenant --mine`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := ClusterResources["namespaces"]
+			if err := filter.complete(opts); err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			if err := filter.namespaces.complete(ctx, dyn); err != nil {
+				return err
+			}
+			return listResources(ctx, dyn, args[0], opts, filter, printFlags, ioStreams)
+		},
+	}
+
+	printFlags.AddFlags(cmd)
+	cmd.Flags().BoolVar(&filter.namespaces.mine, "mine", false, "List only the sandbox of the current user")
+
+	return cmd
+}
+
+// updateSandboxes sets spec.namespaces.sandboxes.enabled and prints the change.
+func updateSandboxes(
+	cmd *cobra.Command,
+	dyn dynamic.Interface,
+	tenantName string,
+	enabled bool,
+	dryRun cmdutil.DryRunStrategy,
+	ioStreams genericiooptions.IOStreams,
+) error {
+	before, after, err := updateTenantSpec(cmd.Context(), dyn, tenantName, sandboxFields, dryRun,
+		func(field map[string]interface{}) error {
+			field["enabled"] = enabled
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	if err := printFieldDiff(ioStreams.Out, "sandboxes", before, after); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return printPatchResult(ioStreams.Out, tenantName, before, after, dryRun)
+}
+
+// isSandboxMember reports whether user is listed as an owner or editor of the
+// tenant, the users the operator creates sandboxes for.
+func isSandboxMember(tenant *unstructured.Unstructured, user string) bool {
+	for _, m := range extractMembers(tenant) {
+		if m.Kind == "User" && m.Name == user && m.Role != "viewer" {
+			return true
+		}
+	}
+	return false
+}
+
+// sandboxOf returns the sandbox namespace of user published in the Tenant status.
+func sandboxOf(tenant *unstructured.Unstructured, user string) string {
+	for _, ns := range extractTenantNamespaces(tenant) {
+		if ns.kind == namespaceTypeSandbox && ns.owner == user {
+			return ns.name
+		}
+	}
+	return ""
+}
+
+// waitForSandbox waits until the sandbox of user is present in (present) or
+// absent from the Tenant status, and returns its namespace when present.
+func waitForSandbox(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantName string,
+	user string,
+	present bool,
+	timeout time.Duration,
+) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var namespace string
+	_, err := waitForTenant(ctx, dyn, tenantName, func(u *unstructured.Unstructured) bool {
+		namespace = sandboxOf(u, user)
+		return (namespace != "") == present
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("timed out waiting for status.deployedSandboxes of tenant %q to reflect the change",
+				tenantName)
+		}
+		return "", err
+	}
+	return namespace, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+)
+
+// readyConditionTypes are conditions that signal a problem when they are not True.
+var readyConditionTypes = []string{"Ready", "Available", "Reconciled", "ReconcileSuccess"}
+
+// failureConditionSuffixes mark conditions that signal a problem when they are True.
+var failureConditionSuffixes = []string{"Failed", "Failure", "Error", "Degraded"}
+
+func newStatusCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <tenant>",
+		Short: "Show the status conditions of a Tenant",
+		Long: `Show the status conditions of a Tenant with their reasons, messages and
+transition times.
+
+The generation of the Tenant is compared with the generation last observed by
+the operator, so a status that doesn't reflect the latest spec can be spotted.`,
+		Example: `  # Show the status of my-tenant
+  kubectl tenant status my-tenant`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			tenant, err := getTenant(cmd.Context(), dyn, args[0], nil)
+			if err != nil {
+				return err
+			}
+			return printTenantStatus(ioStreams.Out, tenant)
+		},
+	}
+
+	return cmd
+}
+
+func printTenantStatus(out io.Writer, tenant *unstructured.Unstructured) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+
+	observed := "<unknown>"
+	if g, ok := observedGeneration(tenant); ok {
+		observed = fmt.Sprint(g)
+	}
+	stale := "no"
+	if isTenantStatusStale(tenant) {
+		stale = "yes, the operator has not reconciled the latest spec"
+	}
+	if _, err := fmt.Fprintf(w, "Name:\t%s\nGeneration:\t%d\nObserved Generation:\t%s\nStale:\t%s\n",
+		tenant.GetName(), tenant.GetGeneration(), observed, stale); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(tenant.Object, "status", "conditions")
+	if len(conditions) == 0 {
+		if _, err := fmt.Fprintln(out, "Conditions:  <none>"); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(out, "Conditions:"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "  TYPE\tSTATUS\tREASON\tMESSAGE\tLAST TRANSITION"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n",
+			conditionField(cond, "type"),
+			conditionField(cond, "status"),
+			conditionField(cond, "reason"),
+			conditionField(cond, "message"),
+			transitionTime(conditionField(cond, "lastTransitionTime")),
+		); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+// warnTenantStatus reports a stale Tenant status and failing conditions, so that
+// allow-lists which the operator hasn't reconciled yet aren't taken at face value.
+func warnTenantStatus(out io.Writer, tenant *unstructured.Unstructured) {
+	if isTenantStatusStale(tenant) {
+		observed, _ := observedGeneration(tenant)
+		_, _ = fmt.Fprintf(out,
+			"Warning: status of tenant %q is stale: generation %d has not been reconciled yet (observed generation %d)\n",
+			tenant.GetName(), tenant.GetGeneration(), observed)
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(tenant.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || !isFailureCondition(cond) {
+			continue
+		}
+		detail := conditionField(cond, "reason")
+		if msg := conditionField(cond, "message"); msg != "" {
+			detail += ": " + msg
+		}
+		_, _ = fmt.Fprintf(out, "Warning: tenant %q condition %s is %s (%s)\n",
+			tenant.GetName(), conditionField(cond, "type"), conditionField(cond, "status"), detail)
+	}
+}
+
+// observedGeneration returns status.observedGeneration, falling back to the
+// highest observedGeneration recorded on the status conditions.
+func observedGeneration(tenant *unstructured.Unstructured) (int64, bool) {
+	if g, found, err := unstructured.NestedInt64(tenant.Object, "status", "observedGeneration"); err == nil && found {
+		return g, true
+	}
+
+	var latest int64
+	found := false
+	conditions, _, _ := unstructured.NestedSlice(tenant.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if g, ok := cond["observedGeneration"].(int64); ok && g >= latest {
+			latest, found = g, true
+		}
+	}
+	return latest, found
+}
+
+func isTenantStatusStale(tenant *unstructured.Unstructured) bool {
+	observed, ok := observedGeneration(tenant)
+	return ok && observed < tenant.GetGeneration()
+}
+
+func isFailureCondition(cond map[string]interface{}) bool {
+	condType := conditionField(cond, "type")
+	status := conditionField(cond, "status")
+
+	for _, t := range readyConditionTypes {
+		if condType == t {
+			return status != "True"
+		}
+	}
+	for _, suffix := range failureConditionSuffixes {
+		if strings.HasSuffix(condType, suffix) {
+			return status == "True"
+		}
+	}
+	return false
+}
+
+func conditionField(cond map[string]interface{}, key string) string {
+	v, _ := cond[key].(string)
+	return v
+}
+
+// transitionTime renders an RFC 3339 timestamp together with its age.
+func transitionTime(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return ts
+	}
+	return fmt.Sprintf("%s (%s ago)", ts, duration.HumanDuration(time.Since(t)))
+}
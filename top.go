@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+)
+
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// quotaResourcesFor are the hard limits usage is compared to, in order of preference.
+var quotaResourcesFor = map[corev1.ResourceName][]corev1.ResourceName{
+	corev1.ResourceCPU:    {"limits.cpu", "requests.cpu", corev1.ResourceCPU},
+	corev1.ResourceMemory: {"limits.memory", "requests.memory", corev1.ResourceMemory},
+}
+
+// podUsage is the current CPU and memory usage of one pod.
+type podUsage struct {
+	namespace string
+	name      string
+	usage     corev1.ResourceList
+}
+
+// tenantPodUsage is the pod usage of a tenant with the hard limits of its quota.
+type tenantPodUsage struct {
+	namespaces []string
+	pods       []podUsage
+	hard       corev1.ResourceList
+}
+
+func newTopCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "Show the CPU and memory usage of a Tenant",
+		Long: `Show the CPU and memory usage of a Tenant, read from the metrics API (metrics.k8s.io).
+
+Usage is summed per namespace and for the whole tenant, and shown as a
+percentage of the hard limits of the tenant's Quota CR: limits.cpu and
+limits.memory, or requests.cpu and requests.memory when those aren't limited.`,
+	}
+
+	cmd.AddCommand(newTopPodsCmd(configFlags, ioStreams))
+	cmd.AddCommand(newTopNamespacesCmd(configFlags, ioStreams))
+
+	return cmd
+}
+
+func newTopPodsCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pods <tenant>",
+		Aliases: []string{"pod", "po"},
+		Short:   "Show the CPU and memory usage of the pods of a Tenant",
+		Example: `  # Show the usage of all pods of my-tenant
+  kubectl tenant top pods my-tenant`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			usage, err := fetchTenantPodUsage(cmd.Context(), configFlags, args[0], ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			return printTopPods(ioStreams.Out, usage.pods, usage.hard)
+		},
+	}
+
+	return cmd
+}
+
+func newTopNamespacesCmd(
+	configFlags *genericclioptions.ConfigFlags,
+	ioStreams genericiooptions.IOStreams,
+) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "namespaces <tenant>",
+		Aliases: []string{"namespace", "ns"},
+		Short:   "Show the CPU and memory usage of the namespaces of a Tenant",
+		Example: `  # Show the usage of each namespace of my-tenant
+  kubectl tenant top namespaces my-tenant`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			usage, err := fetchTenantPodUsage(cmd.Context(), configFlags, args[0], ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			return printTopNamespaces(ioStreams.Out, usage)
+		},
+	}
+
+	return cmd
+}
+
+// fetchTenantPodUsage reads the pod metrics of every tenant namespace, sorted by
+// namespace and name, and the hard limits of the tenant's quota, if it has one.
+func fetchTenantPodUsage(
+	ctx context.Context,
+	configFlags *genericclioptions.ConfigFlags,
+	tenantName string,
+	warnOut io.Writer,
+) (*tenantPodUsage, error) {
+	cfg, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := getTenant(ctx, dyn, tenantName, warnOut)
+	if err != nil {
+		return nil, err
+	}
+	out := &tenantPodUsage{namespaces: extractNamespaceNames(tenant)}
+	quota, err := getTenantQuota(ctx, dyn, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if quota != nil {
+		if out.hard, err = quotaHard(quota); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ns := range out.namespaces {
+		list, err := dyn.Resource(podMetricsGVR).Namespace(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("metrics API not available: %w", err)
+			}
+			return nil, fmt.Errorf("list pod metrics in namespace %q: %w", ns, err)
+		}
+		for _, item := range list.Items {
+			usage, err := podMetricsUsage(item)
+			if err != nil {
+				return nil, err
+			}
+			out.pods = append(out.pods, podUsage{namespace: ns, name: item.GetName(), usage: usage})
+		}
+	}
+
+	pods := out.pods
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].namespace != pods[j].namespace {
+			return pods[i].namespace < pods[j].namespace
+		}
+		return pods[i].name < pods[j].name
+	})
+	return out, nil
+}
+
+// podMetricsUsage sums the usage of the containers of a PodMetrics object.
+func podMetricsUsage(item unstructured.Unstructured) (corev1.ResourceList, error) {
+	containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+
+	total := corev1.ResourceList{}
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		usage, _, _ := unstructured.NestedStringMap(container, "usage")
+		for name, value := range usage {
+			q, err := resource.ParseQuantity(value)
+			if err != nil {
+				return nil, fmt.Errorf("pod metrics %s/%s: invalid %s usage %q: %w",
+					item.GetNamespace(), item.GetName(), name, value, err)
+			}
+			addResources(total, corev1.ResourceList{corev1.ResourceName(name): q})
+		}
+	}
+	return total, nil
+}
+
+func printTopPods(out io.Writer, pods []podUsage, hard corev1.ResourceList) error {
+	rows := []string{"NAMESPACE\tNAME\tCPU(cores)\tMEMORY(bytes)\tCPU%\tMEMORY%"}
+	total, subtotal := corev1.ResourceList{}, corev1.ResourceList{}
+	for i, pod := range pods {
+		rows = append(rows, topRow([]string{pod.namespace, pod.name}, pod.usage, hard))
+		addResources(total, pod.usage)
+		addResources(subtotal, pod.usage)
+
+		// Close each namespace with its subtotal
+		if i == len(pods)-1 || pods[i+1].namespace != pod.namespace {
+			rows = append(rows, topRow([]string{pod.namespace, "(subtotal)"}, subtotal, hard))
+			subtotal = corev1.ResourceList{}
+		}
+	}
+	rows = append(rows, topRow([]string{"TOTAL", ""}, total, hard))
+
+	var b strings.Builder
+	if err := writeTable(&b, rows); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(out, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// printTopNamespaces prints a row for every tenant namespace, including those
+// without running pods.
+func printTopNamespaces(out io.Writer, usage *tenantPodUsage) error {
+	namespaces := slices.Clone(usage.namespaces)
+	sort.Strings(namespaces)
+	namespaces = slices.Compact(namespaces)
+	byNamespace := map[string]corev1.ResourceList{}
+	for _, ns := range namespaces {
+		byNamespace[ns] = corev1.ResourceList{}
+	}
+	total := corev1.ResourceList{}
+	for _, pod := range usage.pods {
+		addResources(byNamespace[pod.namespace], pod.usage)
+		addResources(total, pod.usage)
+	}
+	hard := usage.hard
+
+	rows := []string{"NAMESPACE\tCPU(cores)\tMEMORY(bytes)\tCPU%\tMEMORY%"}
+	for _, ns := range namespaces {
+		rows = append(rows, topRow([]string{ns}, byNamespace[ns], hard))
+	}
+	rows = append(rows, topRow([]string{"TOTAL"}, total, hard))
+
+	var b strings.Builder
+	if err := writeTable(&b, rows); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(out, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// topRow renders the usage after the leading cells, formatted like kubectl top.
+func topRow(cells []string, usage, hard corev1.ResourceList) string {
+	cpu, memory := usage[corev1.ResourceCPU], usage[corev1.ResourceMemory]
+	cells = append(cells,
+		fmt.Sprintf("%dm", cpu.MilliValue()),
+		fmt.Sprintf("%dMi", memory.Value()/(1024*1024)),
+		quotaPercent(cpu, corev1.ResourceCPU, hard),
+		quotaPercent(memory, corev1.ResourceMemory, hard),
+	)
+	return strings.Join(cells, "\t")
+}
+
+// quotaPercent shows used as a percentage of the first hard limit for the resource.
+func quotaPercent(used resource.Quantity, name corev1.ResourceName, hard corev1.ResourceList) string {
+	for _, quotaResource := range quotaResourcesFor[name] {
+		if limit, ok := hard[quotaResource]; ok && !limit.IsZero() {
+			return fmt.Sprintf("%.0f%%", usagePercent(used, limit))
+		}
+	}
+	return "-"
+}
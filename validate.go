@@ -0,0 +1,403 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// fieldManager is the field manager of the server-side applies of the plugin.
+const fieldManager = "kubectl-tenant"
+
+var templateGVR = schema.GroupVersionResource{
+	Group:    "tenantoperator.stakater.com",
+	Version:  "v1alpha1",
+	Resource: "templates",
+}
+
+// Checks reported by validate.
+const (
+	checkSchema    = "schema"
+	checkReference = "reference"
+	checkNamespace = "namespace"
+)
+
+// validationFinding is a problem found in a Tenant manifest.
+type validationFinding struct {
+	Tenant  string `json:"tenant"`
+	Check   string `json:"check"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func newValidateCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var output string
+	manifests := &manifestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "validate -f <manifests>",
+		Short: "Validate Tenant manifests against the cluster before applying them",
+		Long: `Validate Tenant manifests against the cluster before applying them.
+
+Every Tenant in the manifests is checked for
+  schema     the served Tenant CRD schema, with a server-side dry-run apply
+             that rejects unknown fields
+  reference  the StorageClasses, IngressClasses, PriorityClasses, Quota and
+             Templates it refers to exist
+  namespace  none of its namespaces belongs to another tenant, in the cluster
+             or in the manifests
+
+The dry-run apply requires permission to apply Tenants. When it is denied,
+by RBAC or by an admission webhook, the denial is reported as a schema problem
+of that Tenant and the other checks still run. The command exits with a
+non-zero status when problems are found.`,
+		Example: `  # Validate a Tenant manifest
+  kubectl tenant validate -f tenant.yaml
+
+  # Validate a directory of Tenants in CI, with JSON output
+  kubectl tenant validate -f tenants/ -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != "json" && output != "yaml" {
+				return fmt.Errorf("unsupported output format %q: must be one of json, yaml", output)
+			}
+
+			objs, err := manifests.read(ioStreams.In)
+			if err != nil {
+				return err
+			}
+			var tenants []*unstructured.Unstructured
+			for _, obj := range objs {
+				if isTenantManifest(obj) {
+					tenants = append(tenants, obj)
+				}
+			}
+			if len(tenants) == 0 {
+				return errors.New("no Tenant found in the manifests")
+			}
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			findings, err := validateTenants(cmd.Context(), dyn, tenants, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			if err := printValidationFindings(ioStreams.Out, output, len(tenants), findings); err != nil {
+				return err
+			}
+			if len(findings) > 0 {
+				return fmt.Errorf("found %d problems in the Tenant manifests", len(findings))
+			}
+			return nil
+		},
+	}
+
+	manifests.addFlags(cmd, "Files or directories with the Tenant manifests to validate, or - for stdin")
+	_ = cmd.MarkFlagRequired("filename")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format: json or yaml")
+
+	return cmd
+}
+
+// validateTenants runs every check on the Tenant manifests.
+func validateTenants(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenants []*unstructured.Unstructured,
+	warnOut io.Writer,
+) ([]validationFinding, error) {
+	var findings []validationFinding
+	for _, tenant := range tenants {
+		found, err := validateTenantSchema(ctx, dyn, tenant)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, found...)
+
+		if found, err = validateTenantReferences(ctx, dyn, tenant); err != nil {
+			return nil, err
+		}
+		findings = append(findings, found...)
+	}
+
+	found, err := validateNamespaceCollisions(ctx, dyn, tenants, warnOut)
+	if err != nil {
+		return nil, err
+	}
+	return append(findings, found...), nil
+}
+
+// validateTenantSchema dry-run applies the Tenant with strict field validation, so
+// the API server checks it against the schema of the CRD it serves.
+func validateTenantSchema(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenant *unstructured.Unstructured,
+) ([]validationFinding, error) {
+	data, err := json.Marshal(stripServerFields(tenant).Object)
+	if err != nil {
+		return nil, err
+	}
+	force := true
+	_, err = dyn.Resource(tenantGVR).Patch(ctx, tenant.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+		DryRun:          []string{metav1.DryRunAll},
+		FieldManager:    fieldManager,
+		Force:           &force,
+		FieldValidation: metav1.FieldValidationStrict,
+	})
+	if err == nil {
+		return nil, nil
+	}
+
+	// A denial by an admission webhook or by RBAC only fails the check of this
+	// Tenant; errors other than rejections of the request stop validation
+	if apierrors.IsForbidden(err) {
+		return []validationFinding{{
+			Tenant:  tenant.GetName(),
+			Check:   checkSchema,
+			Message: fmt.Sprintf("dry-run apply denied: %v", err),
+		}}, nil
+	}
+	if !apierrors.IsInvalid(err) && !apierrors.IsBadRequest(err) {
+		return nil, fmt.Errorf("dry-run apply tenant %q: %w", tenant.GetName(), err)
+	}
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil || len(status.Status().Details.Causes) == 0 {
+		return []validationFinding{{Tenant: tenant.GetName(), Check: checkSchema, Message: err.Error()}}, nil
+	}
+	var out []validationFinding
+	for _, cause := range status.Status().Details.Causes {
+		out = append(out, validationFinding{
+			Tenant:  tenant.GetName(),
+			Check:   checkSchema,
+			Field:   cause.Field,
+			Message: cause.Message,
+		})
+	}
+	return out, nil
+}
+
+// validateTenantReferences checks that the objects named by the Tenant exist.
+func validateTenantReferences(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenant *unstructured.Unstructured,
+) ([]validationFinding, error) {
+	type reference struct {
+		gvr   schema.GroupVersionResource
+		kind  string
+		field string
+		name  string
+	}
+	var refs []reference
+
+	for _, resourceType := range sortedClusterResources() {
+		opts := ClusterResources[resourceType]
+		if opts.allowListField == "" {
+			continue
+		}
+		names, _, _ := unstructured.NestedStringSlice(tenant.Object, "spec", opts.allowListField, "allowed")
+		for _, name := range names {
+			refs = append(refs, reference{
+				gvr:   opts.resource,
+				kind:  strings.TrimSuffix(opts.listKind, "List"),
+				field: fmt.Sprintf("spec.%s.allowed", opts.allowListField),
+				name:  name,
+			})
+		}
+	}
+	if quota, _, _ := unstructured.NestedString(tenant.Object, "spec", "quota"); quota != "" {
+		refs = append(refs, reference{
+			gvr:   ClusterResources["quotas"].resource,
+			kind:  "Quota",
+			field: "spec.quota",
+			name:  quota,
+		})
+	}
+	instances, _, _ := unstructured.NestedSlice(tenant.Object, "spec", "templateInstances")
+	for i, instance := range instances {
+		m, ok := instance.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(m, "spec", "template"); name != "" {
+			refs = append(refs, reference{
+				gvr:   templateGVR,
+				kind:  "Template",
+				field: fmt.Sprintf("spec.templateInstances[%d].spec.template", i),
+				name:  name,
+			})
+		}
+	}
+
+	var out []validationFinding
+	for _, ref := range refs {
+		_, err := dyn.Resource(ref.gvr).Get(ctx, ref.name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			out = append(out, validationFinding{
+				Tenant:  tenant.GetName(),
+				Check:   checkReference,
+				Field:   ref.field,
+				Message: fmt.Sprintf("%s %q not found", ref.kind, ref.name),
+			})
+		case err != nil:
+			return nil, fmt.Errorf("get %s %q: %w", ref.gvr.Resource, ref.name, err)
+		}
+	}
+	return out, nil
+}
+
+// validateNamespaceCollisions checks that the namespaces of every Tenant manifest
+// belong to no other tenant: neither one in the cluster nor one in the manifests.
+// A Tenant in the manifests replaces the one of the same name in the cluster.
+// Tenants of the cluster whose status is stale or not Ready are reported to warnOut.
+func validateNamespaceCollisions(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenants []*unstructured.Unstructured,
+	warnOut io.Writer,
+) ([]validationFinding, error) {
+	owners := map[string][]string{}
+	inManifests := map[string]bool{}
+	for _, tenant := range tenants {
+		inManifests[tenant.GetName()] = true
+		derived := tenant.DeepCopy()
+		if err := deriveTenantStatus(ctx, derived, nil, io.Discard); err != nil {
+			return nil, err
+		}
+		for _, ns := range extractNamespaceNames(derived) {
+			owners[ns] = append(owners[ns], tenant.GetName())
+		}
+	}
+
+	list, err := dyn.Resource(tenantGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+	for i := range list.Items {
+		tenant := &list.Items[i]
+		if inManifests[tenant.GetName()] {
+			continue
+		}
+		warnTenantStatus(warnOut, tenant)
+		for _, ns := range extractNamespaceNames(tenant) {
+			if _, ok := owners[ns]; ok {
+				owners[ns] = append(owners[ns], tenant.GetName())
+			}
+		}
+	}
+
+	namespaces := make([]string, 0, len(owners))
+	for ns := range owners {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	var out []validationFinding
+	for _, ns := range namespaces {
+		names := owners[ns]
+		for _, tenant := range names {
+			if !inManifests[tenant] {
+				continue
+			}
+			for _, other := range names {
+				if other == tenant {
+					continue
+				}
+				out = append(out, validationFinding{
+					Tenant:  tenant,
+					Check:   checkNamespace,
+					Field:   "spec.namespaces",
+					Message: fmt.Sprintf("namespace %q also belongs to tenant %q", ns, other),
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+// stripServerFields returns a copy of obj without the fields the API server
+// maintains and the last-applied-configuration of kubectl, so it can be applied
+// as a manifest.
+func stripServerFields(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	out := obj.DeepCopy()
+	delete(out.Object, "status")
+	serverFields := []string{"managedFields", "resourceVersion", "uid", "generation", "creationTimestamp", "selfLink"}
+	for _, field := range serverFields {
+		unstructured.RemoveNestedField(out.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(out.Object, "metadata", "annotations", corev1.LastAppliedConfigAnnotation)
+	if len(out.GetAnnotations()) == 0 {
+		unstructured.RemoveNestedField(out.Object, "metadata", "annotations")
+	}
+	return out
+}
+
+func printValidationFindings(out io.Writer, format string, tenants int, findings []validationFinding) error {
+	if findings == nil {
+		findings = []validationFinding{}
+	}
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(findings, "", "    ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(findings)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(data)
+		return err
+	}
+
+	if len(findings) == 0 {
+		if _, err := fmt.Fprintf(out, "No problems found in %d Tenant manifests.\n", tenants); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "TENANT\tCHECK\tFIELD\tMESSAGE"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, f := range findings {
+		field := f.Field
+		if field == "" {
+			field = "<none>"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", f.Tenant, f.Check, field, f.Message); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"sigs.k8s.io/yaml"
+)
+
+// waitForTenant watches the Tenant until cond reports true or ctx is done.
+// The last observed state of the Tenant is returned in either case.
+func waitForTenant(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	tenantName string,
+	cond func(*unstructured.Unstructured) bool,
+) (*unstructured.Unstructured, error) {
+	fieldSelector := fields.OneTermEqualSelector("metadata.name", tenantName).String()
+	lw := &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			return dyn.Resource(tenantGVR).List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			return dyn.Resource(tenantGVR).Watch(ctx, options)
+		},
+	}
+
+	exists := func(store cache.Store) (bool, error) {
+		if _, found, err := store.GetByKey(tenantName); err != nil {
+			return false, err
+		} else if !found {
+			return false, fmt.Errorf("tenant %q not found", tenantName)
+		}
+		return false, nil
+	}
+
+	var last *unstructured.Unstructured
+	reached := func(event watch.Event) (bool, error) {
+		switch event.Type {
+		case watch.Deleted:
+			return false, fmt.Errorf("tenant %q was deleted", tenantName)
+		case watch.Added, watch.Modified:
+			u, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				return false, nil
+			}
+			last = u
+			return cond(u), nil
+		}
+		return false, nil
+	}
+	_, err := watchtools.UntilWithSync(ctx, lw, &unstructured.Unstructured{}, exists, reached)
+	return last, err
+}
+
+func newWaitCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	var forCondition string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "wait <tenant> --for=<condition>",
+		Short: "Wait for a Tenant to reach a condition",
+		Long: `Wait for a Tenant to reach a condition by watching the Tenant CR.
+
+Supported conditions:
+  condition=<type>[=<status>]  a status condition has the given status (default True)
+  namespaces=<count>           at least <count> namespaces are deployed
+  <resource>=<name>            the named resource is available to the tenant, where
+                               <resource> is one of the 'get' resource types
+
+On timeout the last observed Tenant status is printed and the command exits non-zero.`,
+		Example: `  # Wait until my-tenant is ready
+  kubectl tenant wait my-tenant --for=condition=Ready
+
+  # Wait for two namespaces to be deployed
+  kubectl tenant wait my-tenant --for=namespaces=2 --timeout=5m
+
+  # Wait until a storage class is available to the tenant
+  kubectl tenant wait my-tenant --for=storageclass=fast`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tenantName := args[0]
+
+			cond, err := parseWaitCondition(forCondition)
+			if err != nil {
+				return err
+			}
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			last, err := waitForTenant(ctx, dyn, tenantName, cond)
+			if err != nil {
+				if ctx.Err() == nil {
+					return err
+				}
+				if last != nil {
+					status, _, _ := unstructured.NestedMap(last.Object, "status")
+					if out, err := yaml.Marshal(map[string]interface{}{"status": status}); err == nil {
+						_, _ = fmt.Fprintf(ioStreams.ErrOut, "Last observed status of tenant %q:\n%s", tenantName, out)
+					}
+				}
+				return fmt.Errorf("timed out waiting for %s on tenant %q", forCondition, tenantName)
+			}
+
+			if _, err := fmt.Fprintf(ioStreams.Out, "%s/%s condition met\n",
+				tenantGVR.GroupResource().String(), tenantName); err != nil {
+				return fmt.Errorf("failed to write output: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&forCondition, "for", "",
+		"The condition to wait on: condition=<type>[=<status>], namespaces=<count> or <resource>=<name>")
+	cmd.Flags().DurationVar(&timeout, "timeout", 30*time.Second, "The length of time to wait before giving up")
+	_ = cmd.MarkFlagRequired("for")
+
+	return cmd
+}
+
+// parseWaitCondition turns a --for expression into a check on the Tenant.
+func parseWaitCondition(expr string) (func(*unstructured.Unstructured) bool, error) {
+	kind, value, ok := strings.Cut(expr, "=")
+	if !ok || value == "" {
+		return nil, fmt.Errorf("invalid --for %q: expected <kind>=<value>", expr)
+	}
+
+	switch kind {
+	case "condition":
+		condType, status, found := strings.Cut(value, "=")
+		if !found {
+			status = "True"
+		}
+		return func(u *unstructured.Unstructured) bool {
+			c, ok := findCondition(u, condType)
+			s, _ := c["status"].(string)
+			return ok && strings.EqualFold(s, status)
+		}, nil
+	case "namespaces":
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("invalid --for %q: namespaces=<count> expects a non-negative integer", expr)
+		}
+		return func(u *unstructured.Unstructured) bool {
+			deployedNs, _, _ := unstructured.NestedStringSlice(u.Object, "status", "deployedNamespaces")
+			return len(deployedNs) >= count
+		}, nil
+	}
+
+	_, opts, ok := lookupClusterResource(kind)
+	if !ok {
+		return nil, fmt.Errorf("invalid --for %q: unknown condition or resource type %q", expr, kind)
+	}
+	return func(u *unstructured.Unstructured) bool {
+		return slices.Contains(opts.extractTenantResources(u), value)
+	}, nil
+}
+
+// findCondition returns the status condition of the given type, if present.
+func findCondition(u *unstructured.Unstructured, condType string) (map[string]interface{}, bool) {
+	conditions, _, _ := unstructured.NestedSlice(u.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _ := cond["type"].(string); strings.EqualFold(t, condType) {
+			return cond, true
+		}
+	}
+	return nil, false
+}
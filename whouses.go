@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/cli-runtime/pkg/genericiooptions"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/kubectl/pkg/cmd/get"
+)
+
+// tenantMatch is a tenant that permits a resource, with the status fields listing it.
+type tenantMatch struct {
+	tenant string
+	fields []string
+}
+
+func newWhoUsesCmd(configFlags *genericclioptions.ConfigFlags, ioStreams genericiooptions.IOStreams) *cobra.Command {
+	printFlags := get.NewGetPrintFlags()
+
+	cmd := &cobra.Command{
+		Use:   "who-uses <resource> <name>",
+		Short: "Find the tenants permitted to use a resource",
+		Long: `Find the tenants permitted to use a cluster-scoped resource, or owning a namespace.
+
+Every Tenant CR is read and its status is checked for the resource, the same way
+'kubectl tenant get' does. The status field that lists the resource is shown
+next to each tenant.
+
+Supported resources: storageclasses, ingressclasses, priorityclasses, quotas
+and namespaces (singular names are accepted too).`,
+		Example: `  # Which tenants may use the "fast" StorageClass?
+  kubectl tenant who-uses storageclass fast
+
+  # Which tenant owns a namespace?
+  kubectl tenant who-uses namespace foo-prod -o json`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			resourceType, opts, ok := lookupClusterResource(args[0])
+			if !ok {
+				return fmt.Errorf("unsupported resource type %q", args[0])
+			}
+			name := args[1]
+
+			cfg, err := configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+			dyn, err := dynamic.NewForConfig(cfg)
+			if err != nil {
+				return err
+			}
+
+			matches, err := findTenantsUsing(cmd.Context(), dyn, opts, name, ioStreams.ErrOut)
+			if err != nil {
+				return err
+			}
+			return printTenantMatches(resourceType, name, matches, printFlags, ioStreams)
+		},
+	}
+
+	printFlags.AddFlags(cmd)
+	return cmd
+}
+
+// findTenantsUsing lists all Tenant CRs and returns those whose status permits name.
+// Tenants whose status is stale or not Ready are reported to warnOut.
+func findTenantsUsing(
+	ctx context.Context,
+	dyn dynamic.Interface,
+	opts getOptions,
+	name string,
+	warnOut io.Writer,
+) ([]tenantMatch, error) {
+	list, err := dyn.Resource(tenantGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list tenants: %w", err)
+	}
+
+	var matches []tenantMatch
+	for i := range list.Items {
+		tenant := &list.Items[i]
+		warnTenantStatus(warnOut, tenant)
+		if !slices.Contains(opts.extractTenantResources(tenant), name) {
+			continue
+		}
+		matches = append(matches, tenantMatch{tenant: tenant.GetName(), fields: matchedStatusFields(tenant, opts, name)})
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].tenant < matches[j].tenant
+	})
+	return matches, nil
+}
+
+// matchedStatusFields returns the Tenant status fields that list name.
+func matchedStatusFields(u *unstructured.Unstructured, opts getOptions, name string) []string {
+	if opts.statusField != "" {
+		return []string{"status." + opts.statusField + ".available"}
+	}
+
+	// Namespaces are listed either as deployed namespaces or as per-user sandboxes
+	var out []string
+	deployedNs, _, _ := unstructured.NestedStringSlice(u.Object, "status", "deployedNamespaces")
+	if slices.Contains(deployedNs, name) {
+		out = append(out, "status.deployedNamespaces")
+	}
+	sandboxes, _, _ := unstructured.NestedMap(u.Object, "status", "deployedSandboxes")
+	for user, val := range sandboxes {
+		if ns, ok := val.(string); ok && strings.TrimSpace(ns) == name {
+			out = append(out, "status.deployedSandboxes."+user)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func printTenantMatches(
+	resourceType string,
+	name string,
+	matches []tenantMatch,
+	printFlags *get.PrintFlags,
+	ioStreams genericiooptions.IOStreams,
+) error {
+	// If an output format is specified (-o json, -o yaml, etc.), use kubectl printers
+	if printFlags.OutputFormat != nil && *printFlags.OutputFormat != "" && *printFlags.OutputFormat != "wide" {
+		items := make([]unstructured.Unstructured, 0, len(matches))
+		for _, m := range matches {
+			fields := make([]interface{}, 0, len(m.fields))
+			for _, f := range m.fields {
+				fields = append(fields, f)
+			}
+			items = append(items, unstructured.Unstructured{
+				Object: map[string]any{
+					"apiVersion": "tenantoperator.stakater.com/v1beta3",
+					"kind":       "Tenant",
+					"metadata": map[string]any{
+						"name": m.tenant,
+					},
+					"matchedFields": fields,
+				},
+			})
+		}
+
+		list := &unstructured.UnstructuredList{
+			Object: map[string]any{
+				"apiVersion": "tenantoperator.stakater.com/v1beta3",
+				"kind":       "TenantList",
+			},
+			Items: items,
+		}
+
+		p, err := printFlags.ToPrinter()
+		if err != nil {
+			return err
+		}
+		return p.PrintObj(list, ioStreams.Out)
+	}
+
+	if len(matches) == 0 {
+		if _, err := fmt.Fprintf(ioStreams.Out, "No tenants found for %s %q.\n", resourceType, name); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(ioStreams.Out, 0, 4, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "TENANT\tFIELD"); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	for _, m := range matches {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", m.tenant, strings.Join(m.fields, ",")); err != nil {
+			return fmt.Errorf("failed to write output: %w", err)
+		}
+	}
+	return w.Flush()
+}